@@ -0,0 +1,131 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package analyzer provides an analysis.Analyzer that reports likely typos
+// in the doc comments of a Go package. Digram and trigram statistics are
+// gathered from all the doc comments of the package, so each comment is
+// scored against the prose of the package it belongs to, as typo does for
+// a document. Words in typo's list of common English words are never
+// reported, nor are words that name identifiers declared in the package.
+package analyzer // import "robpike.io/cmd/typo/analyzer"
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/analysis"
+
+	"robpike.io/cmd/typo/dict"
+	"robpike.io/cmd/typo/trigram"
+)
+
+const doc = `report likely typos in doc comments
+
+The typo analyzer scores each word of the package's doc comments by how
+unusual its trigrams are relative to the rest of the package's comments,
+following the method of the Unix typo command, and reports words scoring
+at or above the threshold.`
+
+// Analyzer reports likely typos in doc comments.
+var Analyzer = &analysis.Analyzer{
+	Name: "typo",
+	Doc:  doc,
+	Run:  run,
+}
+
+var threshold int
+
+func init() {
+	Analyzer.Flags.IntVar(&threshold, "t", 10, "cutoff threshold; smaller means more words")
+}
+
+var known = make(map[string]bool)
+
+func init() {
+	for _, w := range dict.Words(dict.W2006) {
+		known[w] = true
+	}
+}
+
+// A word is a word in a comment.
+type word struct {
+	text string
+	pos  token.Pos
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	idents := make(map[string]bool)
+	var words []word
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			var doc *ast.CommentGroup
+			switch n := n.(type) {
+			case *ast.Ident:
+				idents[n.Name] = true
+			case *ast.File:
+				doc = n.Doc
+			case *ast.GenDecl:
+				doc = n.Doc
+			case *ast.FuncDecl:
+				doc = n.Doc
+			case *ast.TypeSpec:
+				doc = n.Doc
+			case *ast.ValueSpec:
+				doc = n.Doc
+			case *ast.Field:
+				doc = n.Doc
+			}
+			if doc != nil {
+				for _, c := range doc.List {
+					words = appendWords(words, c)
+				}
+			}
+			return true
+		})
+	}
+	table := trigram.New()
+	for _, w := range words {
+		table.Add(w.text)
+	}
+	reported := make(map[string]bool)
+	for _, w := range words {
+		lower := strings.ToLower(w.text)
+		if known[lower] || idents[w.text] || reported[w.text] {
+			continue
+		}
+		score := int(table.Score(w.text))
+		if score < threshold {
+			continue
+		}
+		reported[w.text] = true
+		pass.Reportf(w.pos, "possible typo %q (score %d)", w.text, score)
+	}
+	return nil, nil
+}
+
+// appendWords appends the words of the comment to the list, trimming
+// punctuation as typo does and dropping anything without a letter.
+func appendWords(words []word, c *ast.Comment) []word {
+	text := c.Text
+	for len(text) > 0 {
+		start := len(c.Text) - len(text)
+		end := strings.IndexFunc(text, unicode.IsSpace)
+		if end < 0 {
+			end = len(text)
+		}
+		tok := text[:end]
+		text = strings.TrimLeftFunc(text[end:], unicode.IsSpace)
+		n := len(tok)
+		tok = strings.TrimLeftFunc(tok, unicode.IsPunct)
+		off := start + n - len(tok)
+		tok = strings.TrimRightFunc(tok, unicode.IsPunct)
+		if strings.HasPrefix(tok, "//") || strings.HasPrefix(tok, "/*") || strings.IndexFunc(tok, unicode.IsLetter) < 0 {
+			continue
+		}
+		words = append(words, word{tok, c.Slash + token.Pos(off)})
+	}
+	return words
+}
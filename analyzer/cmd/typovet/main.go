@@ -0,0 +1,23 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Typovet runs the typo analyzer, which reports likely typos in doc comments.
+// It may be run directly, as in
+//
+//	typovet ./...
+//
+// or as a vet tool:
+//
+//	go vet -vettool=$(which typovet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"robpike.io/cmd/typo/analyzer"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
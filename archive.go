@@ -0,0 +1,126 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Tar and zip archives, including compressed tar files, are opened and
+// each text file within them is scanned. The words of a file in an archive
+// are reported under the name archive!file, as in docs.zip!intro/readme.md.
+// Files that appear to be binary are skipped.
+
+var zipMagic = []byte("PK\x03\x04")
+
+// addArchive adds the words of the text files in the archive and reports
+// whether the file was an archive.
+func addArchive(file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false // Let the caller report the error.
+	}
+	defer f.Close()
+	magic := make([]byte, len(zipMagic))
+	if _, err := io.ReadFull(f, magic); err == nil && bytes.Equal(magic, zipMagic) {
+		addZip(file)
+		return true
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return false
+	}
+	r, err := decode(f)
+	if err != nil {
+		return false
+	}
+	br := bufio.NewReader(r)
+	// A tar header has "ustar" at offset 257.
+	header, _ := br.Peek(262)
+	if len(header) < 262 || string(header[257:262]) != "ustar" {
+		return false
+	}
+	addTar(file, br)
+	return true
+}
+
+// addZip adds the words of the text files in the zip archive.
+func addZip(file string) {
+	z, err := zip.OpenReader(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		failed = true
+		return
+	}
+	defer z.Close()
+	for _, f := range z.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s!%s: %s\n", file, f.Name, err)
+			failed = true
+			continue
+		}
+		addMember(file, f.Name, rc)
+		rc.Close()
+	}
+}
+
+// addTar adds the words of the text files in the tar archive.
+func addTar(file string, r io.Reader) {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s: %s\n", file, err)
+			failed = true
+			return
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		addMember(file, hdr.Name, tr)
+	}
+}
+
+// addMember adds the words of a file within an archive, if it is text.
+// Compressed files are decompressed first.
+func addMember(archive, name string, r io.Reader) {
+	r, err := decode(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s!%s: %s\n", archive, name, err)
+		failed = true
+		return
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s!%s: %s\n", archive, name, err)
+		failed = true
+		return
+	}
+	if isBinary(data) {
+		return
+	}
+	add(archive+"!"+name, bytes.NewReader(data))
+}
+
+// isBinary reports whether the data looks like something other than text:
+// whether there is a NUL byte near the start.
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
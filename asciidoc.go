@@ -0,0 +1,71 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"regexp"
+	"strings"
+)
+
+var asciidocMode = flag.Bool("asciidoc", false, "skip AsciiDoc markup: attributes, listing and literal blocks, and macros")
+
+func init() {
+	addFilter(filter{name: "asciidoc", on: func() bool { return *asciidocMode }, markup: asciidocFilter})
+}
+
+var (
+	// :name: value
+	adocAttribute = regexp.MustCompile(`^:!?[\w-]+!?:(\s|$)`)
+	// [source,go] and other block attribute lists; [[anchor]].
+	adocBlockAttrs = regexp.MustCompile(`^\[.*\]\s*$`)
+	// include::file[], image::file[], and other block macros.
+	adocBlockMacro = regexp.MustCompile(`^[\w-]+::\S*\[.*\]\s*$`)
+	// A delimiter line of a block whose contents are not prose.
+	adocDelimiter = regexp.MustCompile("^(-{4,}|\\.{4,}|\\+{4,}|/{4,}|```+)\\s*$")
+
+	adocInline = []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:link|mailto|xref):[^\s\[]*\[([^\]]*)\]`), // link:url[text]
+		regexp.MustCompile(`\b(?:https?|ftp|irc)://[^\s\[]*\[([^\]]*)\]`), // url[text]
+		regexp.MustCompile(`\b(?:https?|ftp|irc)://\S+`),                  // url
+		regexp.MustCompile(`\b[a-z]+:[^\s\[]*\[[^\]]*\]`),                 // image:file[alt] and others
+		regexp.MustCompile(`<<[^,>]*(?:,([^>]*))?>>`),                     // <<ref,text>>
+		regexp.MustCompile(`\[\[[^\]]*\]\]`),                              // [[anchor]]
+		regexp.MustCompile("`[^`]+`"),                                     // `code`
+		regexp.MustCompile(`\+\+?[^+]+\+\+?`),                             // +passthrough+
+		regexp.MustCompile(`\{[\w-]+\}`),                                  // {attribute}
+	}
+)
+
+// asciidocFilter returns a filter that blanks out AsciiDoc markup: attribute
+// entries, block attribute lists and macros, comments, listing, literal,
+// passthrough, and comment blocks, and inline macros, cross references,
+// code, and attribute references. The text of links and cross references
+// is kept.
+func asciidocFilter() lineFilter {
+	delim := "" // The delimiter that ends the block being skipped.
+	return func(line string) string {
+		trimmed := strings.TrimRight(line, " \t")
+		switch {
+		case delim != "":
+			if trimmed == delim {
+				delim = ""
+			}
+			return blank(line, 0, len(line))
+		case adocDelimiter.MatchString(line):
+			delim = trimmed
+			return blank(line, 0, len(line))
+		case strings.HasPrefix(line, "//"),
+			adocAttribute.MatchString(line),
+			adocBlockAttrs.MatchString(line),
+			adocBlockMacro.MatchString(line):
+			return blank(line, 0, len(line))
+		}
+		for _, re := range adocInline {
+			line = blankMatches(line, re)
+		}
+		return line
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A baseline records the findings in a body of text at some moment, so that
+// later runs may report only what is new. It is a JSON file in the form of
+// the -serve response. A finding matches the baseline if the baseline holds
+// a finding of the same kind for the same word in the same file; the line
+// and column do not matter, so edits elsewhere in a file do not disturb it.
+
+var (
+	baselineFile      = flag.String("baseline", "", "report only findings not recorded in the baseline `file`")
+	writeBaselineFile = flag.String("write-baseline", "", "record all the findings in the baseline `file` instead of reporting them")
+)
+
+// A baselineKey identifies a finding in the baseline.
+type baselineKey struct {
+	kind, file, word string
+}
+
+// writeBaseline writes the repeats, confusable words, mixed spellings, and
+// every occurrence of the unlikely words to the -write-baseline file.
+func writeBaseline(reps, list []*Word) {
+	findings := []jsonFinding{}
+	add := func(w *Word, kind string) {
+		findings = append(findings, jsonFinding{Kind: kind, File: w.file, Line: w.lineNum, Col: w.col, Word: w.text()})
+	}
+	for _, w := range reps {
+		add(w, "repeat")
+	}
+	for _, w := range confused {
+		add(w, "confusable")
+	}
+	for _, m := range mixed {
+		add(m.british, "variant")
+		add(m.american, "variant")
+	}
+	findings = appendTypoFindings(findings, occurrences(list))
+	if err := saveBaseline(*writeBaselineFile, findings); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: writing baseline: %s\n", err)
+		os.Exit(2)
+	}
+}
+
+// appendTypoFindings appends the unlikely words to the findings.
+func appendTypoFindings(findings []jsonFinding, list []*Word) []jsonFinding {
+	for _, w := range list {
+		findings = append(findings, jsonFinding{Kind: "typo", File: w.file, Line: w.lineNum, Col: w.col, Word: w.text(), Score: int(w.score)})
+	}
+	return findings
+}
+
+// readFindings returns the findings in the baseline or JSON report file.
+func readFindings(file string) ([]jsonFinding, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var baseline struct {
+		Findings []jsonFinding `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("reading findings in %s: %s", file, err)
+	}
+	return baseline.Findings, nil
+}
+
+// saveBaseline writes the findings to the baseline file.
+func saveBaseline(file string, findings []jsonFinding) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := encodeFindings(f, findings); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// encodeFindings writes the findings as JSON in the form of a baseline.
+func encodeFindings(w io.Writer, findings []jsonFinding) error {
+	return encodeJSON(w, map[string][]jsonFinding{"findings": findings})
+}
+
+// encodeJSON writes v as indented JSON.
+func encodeJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// applyBaseline returns the findings that are not in the -baseline file,
+// and drops those among the confusable words and mixed spellings. An
+// unlikely word is kept if any of its occurrences that would itself be
+// reported is new, and is reported at the first of those. A mixed spelling
+// is kept if either of its words is new.
+func applyBaseline(reps, list []*Word) ([]*Word, []*Word) {
+	if *baselineFile == "" {
+		return reps, list
+	}
+	isNew := func(w *Word, kind string) bool {
+		return !inBaseline(kind, w.file, w.text())
+	}
+	var newReps []*Word
+	for _, w := range reps {
+		if isNew(w, "repeat") {
+			newReps = append(newReps, w)
+		}
+	}
+	var newConfused []*Word
+	for _, w := range confused {
+		if isNew(w, "confusable") {
+			newConfused = append(newConfused, w)
+		}
+	}
+	confused = newConfused
+	var newMixed []mixture
+	for _, m := range mixed {
+		if isNew(m.british, "variant") || isNew(m.american, "variant") {
+			newMixed = append(newMixed, m)
+		}
+	}
+	mixed = newMixed
+	all := wordsByText()
+	var newList []*Word
+	for _, w := range list {
+		for _, o := range all[w.text()] {
+			if (o == w || scoresAs(o, w)) && isNew(o, "typo") {
+				newList = append(newList, o)
+				break
+			}
+		}
+	}
+	return newReps, newList
+}
+
+// baseline holds the findings of the -baseline file, once read.
+var baseline map[baselineKey]bool
+
+// inBaseline reports whether the -baseline file records a finding of the
+// kind for the word in the file.
+func inBaseline(kind, file, word string) bool {
+	if *baselineFile == "" {
+		return false
+	}
+	if baseline == nil {
+		findings, err := readFindings(*baselineFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			os.Exit(2)
+		}
+		baseline = make(map[baselineKey]bool)
+		for _, f := range findings {
+			baseline[baselineKey{f.Kind, f.File, f.Word}] = true
+		}
+	}
+	return baseline[baselineKey{kind, file, word}]
+}
+
+// scoresAs reports whether the occurrence o of the unlikely word w would be
+// reported in its own right: it is not suppressed, and its score, which
+// with -per-file is that of its own file, is as high as the threshold or,
+// if w scored lower, w's.
+func scoresAs(o, w *Word) bool {
+	return o.candidate() && o.score >= min(*threshold, w.score)
+}
@@ -0,0 +1,130 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// With -cache, the words found in each file are saved in the typo
+// subdirectory of the user's cache directory, named by a hash of the file's
+// contents and of the flags that affect how it is read. A later run finds
+// the words there instead of reading the file again, as long as it is
+// unchanged. Only the statistics must then be computed afresh. The cache
+// directory may be removed at any time.
+
+var useCache = flag.Bool("cache", false, "cache the words of each file, keyed by its contents, to speed later runs")
+
+// maxCached bounds the size of the files whose words are cached; bigger
+// ones are read a line at a time, as usual.
+const maxCached = 64 << 20
+
+// A cachedWord is the record of a word in the cache.
+type cachedWord struct {
+	Text    string
+	LineNum int
+	ByteNum int
+	Col     int
+}
+
+// cacheDir returns the cache directory, or the empty string if there is none.
+func cacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "typo")
+}
+
+// cacheKey returns the name of the cache entry for the contents of the file.
+func cacheKey(file string, data []byte) string {
+	h := sha256.New()
+	// Everything that affects which words are found, and where.
+	fmt.Fprintf(h, "typo cache 1\n%t %t %t %t %t %t %t %t %t %t %q %d %q\n",
+		*filterHTML, *htmlPre, *markdownMode, *rstMode, *asciidocMode, *orgMode, *mailMode, *commitMsg != "",
+		*splitIdents, *splitHyphens, *colMode, *tabWidth, *encodingName)
+	if *wordChars != "" || *breakChars != "" || *stripPossessive {
+		fmt.Fprintf(h, "word %q break %q possessive %t\n", *wordChars, *breakChars, *stripPossessive)
+	}
+	if *skipURLs || *skipEmails || *skipPaths || *skipHashes {
+		fmt.Fprintf(h, "skip %t %t %t %t\n", *skipURLs, *skipEmails, *skipPaths, *skipHashes)
+	}
+	if *commentsMode || *valuesMode || *stringsMode {
+		fmt.Fprintf(h, "comments %t values %t strings %t %q %q %q\n", *commentsMode, *valuesMode, *stringsMode, *valueKeys, strings.ToLower(filepath.Ext(file)), strings.ToLower(filepath.Base(file)))
+	}
+	h.Write(data)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// addCached adds the words of the file, using the cache if it can, and
+// reports whether it did.
+func addCached(file string) bool {
+	dir := cacheDir()
+	if !*useCache || *lowMem || *poMode != "" || *subtitlesMode || *csvCols != "" || *jsonPath != "" || dir == "" {
+		return false
+	}
+	info, err := os.Stat(file)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > maxCached {
+		return false
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	path := filepath.Join(dir, cacheKey(file, data))
+	if entry, err := os.ReadFile(path); err == nil {
+		var cached []cachedWord
+		if gob.NewDecoder(bytes.NewReader(entry)).Decode(&cached) == nil {
+			for _, c := range cached {
+				appendCachedWord(c, file)
+			}
+			recordSuppressions(file, data)
+			totals.files++
+			totals.lines += countLines(data)
+			return true
+		}
+	}
+	n := len(words)
+	add(file, bytes.NewReader(data))
+	cached := make([]cachedWord, 0, len(words)-n)
+	for _, w := range words[n:] {
+		cached = append(cached, cachedWord{w.text(), w.lineNum, w.byteNum, w.col})
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cached); err != nil {
+		return true
+	}
+	// Write and rename, so a concurrent run never sees a partial entry.
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return true
+	}
+	tmp, err := os.CreateTemp(dir, "tmp-")
+	if err != nil {
+		return true
+	}
+	_, err = tmp.Write(buf.Bytes())
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return true
+}
+
+// appendCachedWord adds the word from the cache to the list.
+func appendCachedWord(c cachedWord, file string) {
+	words = append(words, newWord(c.Text, file, c.LineNum, c.ByteNum, c.Col))
+}
@@ -0,0 +1,200 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"robpike.io/cmd/typo/dict"
+)
+
+// Typo's work is divided among subcommands, named by its first argument:
+//
+//	typo [scan] [flags] files...	report the unlikely words in the files
+//	typo train [-o model.bin] [flags] corpus...	build a model for -model
+//	typo serve [flags] [address]	serve HTTP requests, as with -serve
+//	typo fix [flags] files...	correct the words interactively, as with -fix
+//	typo dict [flags] add|list|path [words...]	manage the personal word list
+//	typo stats file ngram...	print n-gram counts from a model or -dump-stats file
+//	typo report merge|diff reports...	combine or compare -format=json reports
+//
+// Without a subcommand, typo scans, so a file named like a subcommand must
+// be given as ./name; all the flags apply. A subcommand accepts only the
+// flags that apply to it: train those that control the input, and fix and
+// serve neither -fix nor -serve, for example.
+
+// A subcommand is one of typo's subcommands.
+type subcommand struct {
+	args  string                 // The arguments, for the usage message.
+	help  string                 // What the subcommand does.
+	flags func(name string) bool // Whether the flag of that name applies.
+}
+
+// commands holds the subcommands.
+var commands = map[string]subcommand{
+	"scan":   {"[flags] files...", "report the unlikely words in the files (the default)", except("fix", "backup", "serve")},
+	"train":  {"[-o model.bin] [flags] corpus...", "build a model from a corpus for use with -model", inputFlag},
+	"serve":  {"[flags] [address]", "serve HTTP requests to check text, as with -serve", except("fix", "backup", "serve", "lsp", "tui", "learn", "stream", "write-baseline", "files", "0", "git", "staged", "diff", "commit-msg")},
+	"fix":    {"[flags] files...", "correct the unlikely words interactively, as with -fix", except("fix", "serve", "lsp", "tui", "stream")},
+	"dict":   {"[flags] add|list|path [words...]", "add words to or list the personal list of known words", only("config", "dict", "lang", "v")},
+	"stats":  {"file ngram...", "print the n-gram counts in a model or -dump-stats file", only()},
+	"report": {"[-o file] merge|diff reports...", "merge or diff reports written with -format=json", only("o")},
+}
+
+// only returns a function reporting whether a flag is one of those named.
+func only(names ...string) func(string) bool {
+	return func(name string) bool {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// except returns a function reporting whether a flag is not one of those named.
+func except(names ...string) func(string) bool {
+	in := only(names...)
+	return func(name string) bool {
+		return !in(name)
+	}
+}
+
+// inputFlag reports whether the flag controls how the input is read and
+// split into words, and so applies to train.
+func inputFlag(name string) bool {
+	if name == "commit-msg" {
+		return false
+	}
+	for _, f := range filters {
+		if f.name == name {
+			return true
+		}
+	}
+	return only("encoding", "html-pre", "keys", "word-chars", "break-chars", "strip-possessive", "minlen", "maxlen", "no-gitignore", "v")(name)
+}
+
+// flags is the set of flags parsed from the command line: by default, that
+// of the whole command, and otherwise that of the subcommand.
+var flags = flag.CommandLine
+
+// commandFlags returns the flags of the subcommand. They share the values of
+// the flags of the whole command, so parsing them sets those.
+func commandFlags(name string) *flag.FlagSet {
+	cmd := commands[name]
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	flag.VisitAll(func(f *flag.Flag) {
+		if cmd.flags(f.Name) {
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: typo %s %s\n", name, cmd.args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: typo [command] [flags] [files...]\n\ncommands:\n")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-6s %s\n", name, commands[name].help)
+	}
+	fmt.Fprintf(os.Stderr, "\nflags:\n")
+	flag.PrintDefaults()
+}
+
+// command handles the subcommand named by the first argument, if any. It
+// returns only if typo is to go on to scan or serve, having parsed the
+// flags, and returns the arguments that remain.
+func command(args []string) []string {
+	flag.Usage = usage
+	if len(args) == 0 || commands[args[0]].help == "" {
+		flag.CommandLine.Parse(args)
+		return flag.Args()
+	}
+	name := args[0]
+	if name == "train" {
+		train(args[1:])
+		os.Exit(0)
+	}
+	flags = commandFlags(name)
+	flags.Parse(args[1:])
+	args = flags.Args()
+	switch name {
+	case "serve":
+		if len(args) > 1 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		*serveAddr = ":8080"
+		if len(args) > 0 {
+			*serveAddr = args[0]
+		}
+	case "fix":
+		*fixMode = true
+	case "stats":
+		statsCmd(args)
+		os.Exit(0)
+	case "dict":
+		dictCmd(args)
+		os.Exit(0)
+	case "report":
+		reportCmd(args)
+		os.Exit(0)
+	}
+	return args
+}
+
+// dictCmd implements the dict subcommand:
+//
+//	typo dict add words...	adds the words, or those on standard input, to the personal list
+//	typo dict list	prints the personal list
+//	typo dict path	prints the name of the file holding the personal list
+func dictCmd(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "usage: typo dict add|list|path [words...]\n")
+		os.Exit(2)
+	}
+	file := localWordsFile()
+	switch args[0] {
+	case "add":
+		configure()
+		loadDictionaries()
+		list := args[1:]
+		if len(list) == 0 {
+			learnLists(nil)
+			return
+		}
+		if err := learn(list); err != nil {
+			fmt.Fprintf(os.Stderr, "typo: learning: %s\n", err)
+			os.Exit(2)
+		}
+	case "list":
+		data, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			os.Exit(2)
+		}
+		if words := dict.Words(data); len(words) > 0 {
+			fmt.Println(strings.Join(words, "\n"))
+		}
+	case "path":
+		fmt.Println(file)
+	default:
+		fmt.Fprintf(os.Stderr, "typo: unknown dict command %q; want add, list, or path\n", args[0])
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestCommandFlags(t *testing.T) {
+	tests := []struct {
+		command string
+		accept  []string
+		reject  []string
+	}{
+		{"scan", []string{"t", "html", "stream", "format"}, []string{"fix", "serve"}},
+		{"train", []string{"html", "po", "skip-urls", "encoding", "stop"}, []string{"fix", "serve", "t", "model", "format"}},
+		{"serve", []string{"t", "html", "lang"}, []string{"fix", "serve", "lsp", "files"}},
+		{"fix", []string{"t", "backup", "learn"}, []string{"fix", "serve", "lsp"}},
+		{"dict", []string{"lang", "dict"}, []string{"html", "fix", "serve"}},
+		{"stats", nil, []string{"v", "fix"}},
+		{"report", []string{"o"}, []string{"fix", "serve", "format"}},
+	}
+	for _, test := range tests {
+		fs := commandFlags(test.command)
+		for _, name := range test.accept {
+			if fs.Lookup(name) == nil {
+				t.Errorf("typo %s does not accept -%s", test.command, name)
+			}
+		}
+		for _, name := range test.reject {
+			if fs.Lookup(name) != nil {
+				t.Errorf("typo %s accepts -%s", test.command, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+)
+
+var commentsMode = flag.Bool("comments", false, "check only the comments of source files, by their suffixes: C, C++, Java, JavaScript, Rust, Python, shell, and others")
+
+func init() {
+	addFilter(filter{name: "comments", on: func() bool { return *commentsMode }, selects: commentFilter})
+}
+
+// A commentSyntax describes the comments and strings of a language.
+type commentSyntax struct {
+	line   []string    // Start of comments that run to the end of the line.
+	block  [][2]string // Start and end of comments that may span lines.
+	quotes string      // Quotes of strings, which hide comment markers.
+}
+
+var (
+	cSyntax = &commentSyntax{
+		line:   []string{"//"},
+		block:  [][2]string{{"/*", "*/"}},
+		quotes: "\"'`",
+	}
+	rustSyntax = &commentSyntax{
+		line:   []string{"//"},
+		block:  [][2]string{{"/*", "*/"}},
+		quotes: `"`, // ' also marks lifetimes.
+	}
+	pythonSyntax = &commentSyntax{
+		line:   []string{"#"},
+		block:  [][2]string{{`"""`, `"""`}, {"'''", "'''"}}, // Docstrings.
+		quotes: "\"'",
+	}
+	shellSyntax = &commentSyntax{
+		line:   []string{"#"},
+		quotes: "\"'",
+	}
+)
+
+// commentSyntaxes maps file suffixes to the syntax of their comments.
+var commentSyntaxes = map[string]*commentSyntax{
+	".c":     cSyntax,
+	".h":     cSyntax,
+	".cc":    cSyntax,
+	".cpp":   cSyntax,
+	".cxx":   cSyntax,
+	".hpp":   cSyntax,
+	".cs":    cSyntax,
+	".go":    cSyntax,
+	".java":  cSyntax,
+	".js":    cSyntax,
+	".jsx":   cSyntax,
+	".kt":    cSyntax,
+	".scala": cSyntax,
+	".swift": cSyntax,
+	".ts":    cSyntax,
+	".tsx":   cSyntax,
+	".rs":    rustSyntax,
+	".py":    pythonSyntax,
+	".bash":  shellSyntax,
+	".pl":    shellSyntax,
+	".r":     shellSyntax,
+	".rb":    shellSyntax,
+	".sh":    shellSyntax,
+	".zsh":   shellSyntax,
+}
+
+// commentFilter returns a filter that blanks out all but the text of the
+// comments of the file, according to the syntax for its suffix, or nil if
+// the suffix is unknown, in which case the whole file is checked.
+func commentFilter(file string) lineFilter {
+	syn := commentSyntaxes[strings.ToLower(filepath.Ext(file))]
+	if syn == nil {
+		return nil
+	}
+	end := "" // The end of the block comment we are in.
+	return func(line string) string {
+		b := []byte(line)
+		blankBytes := func(i, j int) {
+			for ; i < j; i++ {
+				b[i] = ' '
+			}
+		}
+	Scan:
+		for i := 0; i < len(line); {
+			if end != "" {
+				j := strings.Index(line[i:], end)
+				if j < 0 {
+					break
+				}
+				blankBytes(i+j, i+j+len(end))
+				i += j + len(end)
+				end = ""
+				continue
+			}
+			for _, start := range syn.line {
+				if strings.HasPrefix(line[i:], start) {
+					blankBytes(i, i+len(start))
+					break Scan
+				}
+			}
+			for _, bl := range syn.block {
+				if strings.HasPrefix(line[i:], bl[0]) {
+					blankBytes(i, i+len(bl[0]))
+					i += len(bl[0])
+					end = bl[1]
+					continue Scan
+				}
+			}
+			if q := line[i]; strings.IndexByte(syn.quotes, q) >= 0 {
+				// Skip the string, which ends at the line if not before.
+				j := i + 1
+				for j < len(line) && line[j] != q {
+					if line[j] == '\\' {
+						j++
+					}
+					j++
+				}
+				j = min(j+1, len(line))
+				blankBytes(i, j)
+				i = j
+				continue
+			}
+			if b[i] != '\t' {
+				b[i] = ' '
+			}
+			i++
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// With -commit-msg, typo checks a commit message as a git commit-msg hook:
+//
+//	#!/bin/sh
+//	exec typo -commit-msg "$1"
+//
+// A message is too short to have useful statistics of its own, so unless
+// -model is given, the model model.bin in typo's data directories (see
+// datadir.go) is used; make it with typo train.
+// Unless -fail-over is given, any unlikely word fails the commit.
+
+var commitMsg = flag.String("commit-msg", "", "check the commit message in `file`, as a git commit-msg hook")
+
+func init() {
+	addFilter(filter{name: "commit-msg", on: func() bool { return *commitMsg != "" }, markup: commitFilter})
+}
+
+var (
+	// Signed-off-by: A. Person <a@example.com>
+	commitTrailer = regexp.MustCompile(`^[\w-]+: \S`)
+	// " typo.go | 12 ++++--" and " 2 files changed, 10 insertions(+)"
+	commitDiffStat = regexp.MustCompile(`^ .*\| +(\d+ [+-]*|Bin .*)$|^ \d+ files? changed`)
+)
+
+// The scissors line below which git -v puts the diff.
+const commitScissors = "# ------------------------ >8 ------------------------"
+
+// setupCommitMsg adjusts the flags for checking a commit message.
+func setupCommitMsg() {
+	if *commitMsg == "" {
+		return
+	}
+	if *modelFile == "" {
+		if file, ok := findData("model.bin"); ok {
+			*modelFile = file
+		} else if dir, err := os.UserConfigDir(); err == nil {
+			fmt.Fprintf(os.Stderr, "typo: warning: no model; create one with typo train -o %s\n", filepath.Join(dir, "typo", "model.bin"))
+		}
+	}
+	if *failOver == 0 {
+		*failOver = 1
+	}
+}
+
+// commitFilter returns a filter that blanks out the parts of a commit
+// message that are not prose: comment lines, everything below the scissors
+// line, diff stats, and trailers. A trailer is a "Key: value" line outside
+// the subject whose paragraph holds only trailers so far.
+func commitFilter() lineFilter {
+	subject := true   // In the first paragraph.
+	trailers := false // The paragraph so far is trailers.
+	skip := false     // Below the scissors.
+	return func(line string) string {
+		switch {
+		case line == commitScissors:
+			skip = true
+		case skip, strings.HasPrefix(line, "#"):
+		case strings.TrimSpace(line) == "":
+			if !trailers {
+				subject = false
+			}
+			trailers = true
+			return line
+		case commitDiffStat.MatchString(line):
+			trailers = false
+		case !subject && trailers && commitTrailer.MatchString(line):
+		default:
+			trailers = false
+			return line
+		}
+		return blank(line, 0, len(line))
+	}
+}
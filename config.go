@@ -0,0 +1,278 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A configuration file supplies defaults for flags. Each setting is
+// named by its flag, and settings given on the command line take
+// precedence. Two simple formats are understood, chosen by the file's
+// suffix. The TOML form is
+//
+//	# Comment.
+//	t = 12
+//	html = true
+//	col = "rune"
+//
+// and the YAML form is
+//
+//	t: 12
+//	html: true
+//	col: rune
+//
+// A setting for a repeatable flag may be a list, either ["a", "b"] in
+// TOML or a sequence of "- a" lines following the key in YAML.
+// Underscores in names may be used in place of hyphens.
+// Sections, tables, and nested maps are not supported.
+//
+// A configuration file may come with a repository one has cloned, so it
+// may not set the flags, or the values of flags, that run commands, write
+// or rewrite files, or listen for connections, such as -pdf and
+// -format=sqlite; those must be given on the command line.
+
+var configFile = flag.String("config", "", "read flag defaults from `file` instead of searching for typo.toml or .typo.yml")
+
+// commandLineOnly holds the flags a configuration file may not set.
+var commandLineOnly = map[string]bool{
+	"cache":          true,
+	"config":         true,
+	"dump-stats":     true,
+	"filter-cmd":     true,
+	"fix":            true,
+	"git":            true,
+	"learn":          true,
+	"lsp":            true,
+	"o":              true,
+	"pdf":            true,
+	"serve":          true,
+	"staged":         true,
+	"tui":            true,
+	"write-baseline": true,
+}
+
+// commandLineOnlyValue holds, for flags a configuration file may set, a
+// value it may not set them to.
+var commandLineOnlyValue = map[string]string{
+	"format": "sqlite",
+}
+
+// settable reports whether a configuration file may set the flag to the value.
+func settable(name, value string) bool {
+	v, ok := commandLineOnlyValue[name]
+	return !commandLineOnly[name] && (!ok || v != value)
+}
+
+// configNames lists the names of configuration files, in order of preference.
+var configNames = []string{"typo.toml", ".typo.toml", "typo.yml", ".typo.yml"}
+
+// findConfig returns the path of the configuration file to use, or
+// the empty string if there is none. It looks first in the current
+// directory and then in the typo subdirectory of the user's configuration
+// directory, $XDG_CONFIG_HOME on Unix.
+func findConfig() string {
+	if *configFile != "" {
+		return *configFile
+	}
+	dirs := []string{"."}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "typo"))
+	}
+	for _, dir := range dirs {
+		for _, name := range configNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// configure applies the settings in the configuration file, if any,
+// to the flags that were not set on the command line.
+func configure() {
+	path := findConfig()
+	if path == "" {
+		return
+	}
+	settings, err := readConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		os.Exit(2)
+	}
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for _, s := range settings {
+		name := configName(s.name)
+		if flag.Lookup(name) == nil {
+			fmt.Fprintf(os.Stderr, "typo: %s:%d: unknown setting %q\n", path, s.line, s.name)
+			os.Exit(2)
+		}
+		if commandLineOnly[name] {
+			fmt.Fprintf(os.Stderr, "typo: %s:%d: %s may be set only on the command line\n", path, s.line, s.name)
+			os.Exit(2)
+		}
+		for _, v := range s.values {
+			if !settable(name, v) {
+				fmt.Fprintf(os.Stderr, "typo: %s:%d: %s=%s may be set only on the command line\n", path, s.line, s.name, v)
+				os.Exit(2)
+			}
+		}
+		if set[name] {
+			continue
+		}
+		for _, v := range s.values {
+			if err := flag.Set(name, v); err != nil {
+				fmt.Fprintf(os.Stderr, "typo: %s:%d: %s: %s\n", path, s.line, s.name, err)
+				os.Exit(2)
+			}
+		}
+	}
+	logf("configuration: %s", path)
+}
+
+// configName returns the name of the flag for the setting, in which
+// underscores may stand for hyphens.
+func configName(name string) string {
+	if flag.Lookup(name) == nil {
+		return strings.ReplaceAll(name, "_", "-")
+	}
+	return name
+}
+
+// A setting is a single entry in a configuration file.
+type setting struct {
+	name   string
+	values []string
+	line   int
+}
+
+// readConfig parses the configuration file.
+func readConfig(path string) ([]setting, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	yaml := strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml")
+	var settings []setting
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || yaml && line == "---" {
+			continue
+		}
+		errorf := func(format string, args ...interface{}) error {
+			return fmt.Errorf("%s:%d: %s", path, lineNum, fmt.Sprintf(format, args...))
+		}
+		if yaml && strings.HasPrefix(line, "-") {
+			// An element of the list for the preceding key.
+			if len(settings) == 0 {
+				return nil, errorf("list element without key")
+			}
+			v, err := configValue(strings.TrimSpace(line[1:]))
+			if err != nil {
+				return nil, errorf("%s", err)
+			}
+			s := &settings[len(settings)-1]
+			s.values = append(s.values, v)
+			continue
+		}
+		sep := "="
+		if yaml {
+			sep = ":"
+		}
+		name, value, ok := strings.Cut(line, sep)
+		if !ok {
+			return nil, errorf("expected %q", sep)
+		}
+		name = strings.Trim(strings.TrimSpace(name), `"'`)
+		value = strings.TrimSpace(value)
+		s := setting{name: name, line: lineNum}
+		switch {
+		case value == "" && yaml:
+			// List follows.
+		case strings.HasPrefix(value, "["):
+			if !strings.HasSuffix(value, "]") {
+				return nil, errorf("unterminated list")
+			}
+			for _, elem := range splitList(value[1 : len(value)-1]) {
+				v, err := configValue(elem)
+				if err != nil {
+					return nil, errorf("%s", err)
+				}
+				s.values = append(s.values, v)
+			}
+		default:
+			v, err := configValue(value)
+			if err != nil {
+				return nil, errorf("%s", err)
+			}
+			s.values = []string{v}
+		}
+		settings = append(settings, s)
+	}
+	return settings, scanner.Err()
+}
+
+// configValue returns the text of a single value, unquoting it and removing any trailing comment.
+func configValue(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		q, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", fmt.Errorf("bad string %s", s)
+		}
+		return strconv.Unquote(q)
+	case strings.HasPrefix(s, "'"):
+		// A literal string, with no escapes.
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("bad string %s", s)
+		}
+		return s[1 : end+1], nil
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// splitList splits the body of a list at commas outside quotes.
+func splitList(s string) []string {
+	var elems []string
+	quote := byte(0)
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			elems = append(elems, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		elems = append(elems, last)
+	}
+	return elems
+}
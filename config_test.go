@@ -0,0 +1,161 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var configTests = []struct {
+	file string
+	text string
+	want []setting
+}{
+	{
+		file: "typo.toml",
+		text: `# Comment.
+t = 12
+html = true
+col = "rune"
+
+word-chars = '#'
+ignore_re = ["^x", 'a,b', "c\"d"]
+stop = "stop.txt" # Trailing comment.
+n = 5 # Another.
+`,
+		want: []setting{
+			{"t", []string{"12"}, 2},
+			{"html", []string{"true"}, 3},
+			{"col", []string{"rune"}, 4},
+			{"word-chars", []string{"#"}, 6},
+			{"ignore_re", []string{"^x", "a,b", `c"d`}, 7},
+			{"stop", []string{"stop.txt"}, 8},
+			{"n", []string{"5"}, 9},
+		},
+	},
+	{
+		file: "typo.toml",
+		text: "dict = []\n\"lang\" = \"en_GB\"\n",
+		want: []setting{
+			{"dict", nil, 1},
+			{"lang", []string{"en_GB"}, 2},
+		},
+	},
+	{
+		file: ".typo.yml",
+		text: `---
+# Comment.
+t: 12
+html: true
+col: rune
+ignore-re:
+  - ^x
+  - "a b"
+  - 'c: d' # Comment.
+stop: [a.txt, "b.txt"]
+lang: en # Comment.
+`,
+		want: []setting{
+			{"t", []string{"12"}, 3},
+			{"html", []string{"true"}, 4},
+			{"col", []string{"rune"}, 5},
+			{"ignore-re", []string{"^x", "a b", "c: d"}, 6},
+			{"stop", []string{"a.txt", "b.txt"}, 10},
+			{"lang", []string{"en"}, 11},
+		},
+	},
+}
+
+func writeConfig(t *testing.T, name, text string) string {
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(text), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestReadConfig(t *testing.T) {
+	for _, test := range configTests {
+		settings, err := readConfig(writeConfig(t, test.file, test.text))
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+		if !reflect.DeepEqual(settings, test.want) {
+			t.Errorf("%s:\ngot  %v\nwant %v", test.file, settings, test.want)
+		}
+	}
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	tests := []struct {
+		file string
+		text string
+	}{
+		{"typo.toml", "t 12\n"},
+		{"typo.toml", "t: 12\n"},
+		{"typo.toml", "stop = [\"a\", \"b\"\n"},
+		{"typo.toml", "col = \"rune\n"},
+		{"typo.toml", "col = 'rune\n"},
+		{"typo.yml", "- a\n"},
+		{"typo.yml", "t = 12\n"},
+	}
+	for _, test := range tests {
+		if _, err := readConfig(writeConfig(t, test.file, test.text)); err == nil {
+			t.Errorf("%s %q: no error", test.file, test.text)
+		}
+	}
+}
+
+func TestConfigName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"t", "t"},
+		{"word-chars", "word-chars"},
+		{"word_chars", "word-chars"},
+		{"ignore_re", "ignore-re"},
+		{"filter_cmd", "filter-cmd"},
+		{"no_such_flag", "no-such-flag"},
+	}
+	for _, test := range tests {
+		if got := configName(test.name); got != test.want {
+			t.Errorf("configName(%q) = %q; want %q", test.name, got, test.want)
+		}
+	}
+	for _, name := range []string{"filter_cmd", "write_baseline", "dump_stats"} {
+		if !commandLineOnly[configName(name)] {
+			t.Errorf("%s may be set in a configuration file", name)
+		}
+	}
+}
+
+func TestSettable(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{"t", "12", true},
+		{"html", "true", true},
+		{"format", "json", true},
+		{"cache", "true", false},
+		{"pdf", "true", false},
+		{"git", "true", false},
+		{"staged", "true", false},
+		{"format", "sqlite", false},
+		{"filter-cmd", "cat", false},
+		{"o", "out.txt", false},
+	}
+	for _, test := range tests {
+		if got := settable(test.name, test.value); got != test.want {
+			t.Errorf("settable(%q, %q) = %t; want %t", test.name, test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"strings"
+
+	"robpike.io/cmd/typo/dict"
+)
+
+var confusables = flag.Bool("confusables", false, "note each use of a word that is easily confused with another, such as their and there")
+
+// confusableWith maps each confusable word to the words it may be confused with.
+var confusableWith map[string][]string
+
+// confused holds the uses of confusable words found in the input.
+var confused []*Word
+
+// loadConfusables reads the built-in groups of confusable words.
+func loadConfusables() {
+	confusableWith = make(map[string][]string)
+	scanner := bufio.NewScanner(bytes.NewReader(dict.Confusables))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		group := strings.Fields(line)
+		for _, w := range group {
+			for _, x := range group {
+				if x != w {
+					confusableWith[fold(w)] = append(confusableWith[fold(w)], x)
+				}
+			}
+		}
+	}
+}
+
+// findConfusables returns copies of the words of the input that are
+// confusable, on changed lines if there is a diff.
+func findConfusables() []*Word {
+	if confusableWith == nil {
+		loadConfusables()
+	}
+	var list []*Word
+	for _, word := range words {
+		if confusableWith[word.lower()] != nil && inDiff(word) {
+			w := *word
+			list = append(list, &w)
+		}
+	}
+	return list
+}
+
+// confusableNote returns the note describing the confusable word.
+func confusableNote(w *Word) string {
+	others := confusableWith[w.lower()]
+	s := strings.Join(others, ", ")
+	if n := len(others); n > 1 {
+		s = strings.Join(others[:n-1], ", ") + " or " + others[n-1]
+	}
+	return "may be confused with " + s
+}
+
+// printConfusable prints the confusable word with its note and the line
+// it is in.
+func printConfusable(w *Word) {
+	printFinding(w, " ("+confusableNote(w)+")")
+	if useColor {
+		return // printFinding showed the line.
+	}
+	if line := fileLine(w.file, w.lineNum); line != "" {
+		fmt.Fprintf(out, "\t%s\n", strings.TrimSpace(line))
+	}
+}
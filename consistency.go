@@ -0,0 +1,63 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"robpike.io/cmd/typo/dict"
+)
+
+var consistency = flag.Bool("consistency", false, "report words spelled in both their British and American forms, such as colour and color")
+
+// A mixture records the first uses of the British and American spellings
+// of a word in the input.
+type mixture struct {
+	british, american *Word
+}
+
+// mixed holds the mixtures found in the input.
+var mixed []mixture
+
+// findMixtures returns the words of the input used in both their British
+// and American spellings, in order of the British word's first use.
+func findMixtures() []mixture {
+	american := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(dict.Variants))
+	for scanner.Scan() {
+		f := strings.Fields(scanner.Text())
+		if len(f) == 2 && !strings.HasPrefix(f[0], "#") {
+			american[f[0]] = f[1]
+		}
+	}
+	first := make(map[string]*Word)
+	for _, word := range words {
+		if first[word.lower()] == nil {
+			first[word.lower()] = word
+		}
+	}
+	var list []mixture
+	for b, a := range american {
+		if first[b] != nil && first[a] != nil {
+			w1, w2 := *first[b], *first[a]
+			list = append(list, mixture{&w1, &w2})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return before(list[i].british, list[j].british)
+	})
+	return list
+}
+
+// note returns the note describing the mixture, to follow the British word.
+func (m mixture) note() string {
+	a := m.american
+	return fmt.Sprintf("also spelled %s at %s:%d:%d", a.text(), a.file, a.lineNum, a.col)
+}
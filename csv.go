@@ -0,0 +1,149 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var csvCols = flag.String("csv-cols", "", "check only the comma-separated `columns`, by number from 1 or by header name, of .csv and .tsv files")
+
+func init() {
+	addFilter(filter{name: "csv-cols", on: func() bool { return *csvCols != "" }, selects: csvFilter})
+}
+
+var (
+	csvNumbers = make(map[int]bool)    // The columns selected by number, from 0.
+	csvNames   = make(map[string]bool) // The columns selected by header name.
+)
+
+// setCSVCols parses -csv-cols.
+func setCSVCols() {
+	if *csvCols == "" {
+		return
+	}
+	for _, col := range strings.Split(*csvCols, ",") {
+		col = strings.TrimSpace(col)
+		if col == "" {
+			continue
+		}
+		n, err := strconv.Atoi(col)
+		switch {
+		case err != nil:
+			csvNames[col] = true
+		case n < 1:
+			fmt.Fprintf(os.Stderr, "typo: bad -csv-cols column %d; columns are numbered from 1\n", n)
+			os.Exit(2)
+		default:
+			csvNumbers[n-1] = true
+		}
+	}
+}
+
+// csvFilter returns a filter that blanks out all but the selected columns
+// of the CSV or TSV file, or nil if the file is not one. If columns are
+// selected by name, the first row is the header that names them and is not
+// checked. It records the row and column of the text as line notes.
+func csvFilter(file string) lineFilter {
+	var sep byte
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		sep = ','
+	case ".tsv":
+		sep = '\t'
+	default:
+		return nil
+	}
+	notes := make(map[int][]textNote)
+	lineNotes[file] = notes
+	lineNum, row, field := 0, 0, 0
+	inQuote := false // Whether we are in a quoted field.
+	var header []string
+	var name strings.Builder // The header name being read.
+	inHeader := func() bool {
+		return row == 1 && len(csvNames) > 0
+	}
+	endField := func() {
+		if inHeader() {
+			header = append(header, strings.TrimSpace(name.String()))
+			name.Reset()
+		}
+	}
+	selected := func() bool {
+		return !inHeader() && (csvNumbers[field] || field < len(header) && csvNames[header[field]])
+	}
+	note := func(byteNum int) {
+		if !selected() {
+			return
+		}
+		col := fmt.Sprint("column ", field+1)
+		if field < len(header) && header[field] != "" {
+			col = header[field]
+		}
+		notes[lineNum] = append(notes[lineNum], textNote{byteNum, fmt.Sprintf("row %d, %s", row, col)})
+	}
+	return func(line string) string {
+		lineNum++
+		v := newValueLine(line)
+		start := !inQuote // Whether a field starts at i.
+		if start {
+			row++
+			field = 0
+		} else {
+			note(1)
+			name.WriteByte('\n')
+		}
+		for i := 0; i < len(line); {
+			c := line[i]
+			if start {
+				start = false
+				note(i + 1)
+				if c == '"' {
+					inQuote = true
+					v.blank(i, i+1)
+					i++
+					continue
+				}
+			}
+			switch {
+			case inQuote && c == '"' && i+1 < len(line) && line[i+1] == '"':
+				// A quote in a quoted field.
+				v.blank(i, i+2)
+				name.WriteByte('"')
+				i += 2
+				continue
+			case inQuote && c == '"':
+				inQuote = false
+				v.blank(i, i+1)
+			case !inQuote && c == sep:
+				v.blank(i, i+1)
+				endField()
+				field++
+				start = true
+			default:
+				if inHeader() {
+					name.WriteByte(c)
+				}
+				if !selected() {
+					v.blank(i, i+1)
+				}
+			}
+			i++
+		}
+		if start {
+			// An empty last field.
+			note(len(line) + 1)
+		}
+		if !inQuote {
+			endField()
+		}
+		return v.String()
+	}
+}
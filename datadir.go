@@ -0,0 +1,53 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Typo's data, such as word lists, keyboard layouts, and the model used for
+// commit messages, is found by name in a list of directories. A file named
+// by a flag is used as is; otherwise typo looks in turn in
+//
+//	the directories listed in $TYPO_DICT_PATH, separated as in $PATH
+//	the typo subdirectory of the user's configuration directory,
+//	  $XDG_CONFIG_HOME/typo on Unix and %AppData%\typo on Windows
+//	share/typo beside the directory holding the typo binary
+//
+// and then falls back to the data built in, if there is any.
+
+// dataDirs returns the directories in which to look for typo's data, in
+// order of preference.
+func dataDirs() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv("TYPO_DICT_PATH")) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "typo"))
+	}
+	if exe, err := os.Executable(); err == nil {
+		if exe, err := filepath.EvalSymlinks(exe); err == nil {
+			dirs = append(dirs, filepath.Join(filepath.Dir(filepath.Dir(exe)), "share", "typo"))
+		}
+	}
+	return dirs
+}
+
+// findData returns the name of the first file with the given name, a path
+// relative to the data directories, that exists in them.
+func findData(name string) (string, bool) {
+	for _, dir := range dataDirs() {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			return file, true
+		}
+	}
+	return "", false
+}
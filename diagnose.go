@@ -0,0 +1,92 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+var diagnoseMode = flag.Bool("diagnose", false, "label unlikely words that are known words with two adjacent letters swapped or a letter doubled or undoubled, or two known words run together")
+
+// diagnose returns a description of the likely error in the word, if
+// swapping two adjacent letters, removing a doubled letter, or doubling a
+// letter makes it a known word, or if it is two known words run together,
+// or the empty string.
+func diagnose(word string) string {
+	r := []rune(fold(word))
+	for i := 0; i+1 < len(r); i++ {
+		if r[i] == r[i+1] {
+			continue
+		}
+		s := append([]rune(nil), r...)
+		s[i], s[i+1] = s[i+1], s[i]
+		if isKnown(string(s)) {
+			return fmt.Sprintf("likely transposition of '%s'", matchCase(word, string(s)))
+		}
+	}
+	for i := 0; i+1 < len(r); i++ {
+		if r[i] != r[i+1] {
+			continue
+		}
+		s := append(append([]rune(nil), r[:i]...), r[i+1:]...)
+		if isKnown(string(s)) {
+			return fmt.Sprintf("likely doubled letter in '%s'", matchCase(word, string(s)))
+		}
+	}
+	for i := range r {
+		if i > 0 && r[i-1] == r[i] || i+1 < len(r) && r[i+1] == r[i] {
+			continue
+		}
+		s := append(append(append([]rune(nil), r[:i+1]...), r[i]), r[i+1:]...)
+		if isKnown(string(s)) {
+			return fmt.Sprintf("likely missing double letter in '%s'", matchCase(word, string(s)))
+		}
+	}
+	if a, b := split(r); a != "" {
+		return fmt.Sprintf("likely missing space: '%s %s'", matchCase(word, a), b)
+	}
+	return ""
+}
+
+// split returns the two known words that the runes run together, or empty
+// strings if there are none. If there are several ways to split them, the
+// most even one is chosen.
+func split(r []rune) (string, string) {
+	best := 0
+	for i := 1; i < len(r); i++ {
+		if min(i, len(r)-i) > min(best, len(r)-best) && isKnown(string(r[:i])) && isKnown(string(r[i:])) {
+			best = i
+		}
+	}
+	if best == 0 {
+		return "", ""
+	}
+	return string(r[:best]), string(r[best:])
+}
+
+// diagnosis returns the suffix with which to print the word: its diagnosis,
+// in parentheses, if there is one and -diagnose or -ocr is set.
+func diagnosis(w *Word) string {
+	if d := diagnosisText(w); d != "" {
+		return " (" + d + ")"
+	}
+	return ""
+}
+
+// diagnosisText returns the diagnosis of the word under -diagnose and -ocr.
+func diagnosisText(w *Word) string {
+	var notes []string
+	if *diagnoseMode {
+		if d := diagnose(w.text()); d != "" {
+			notes = append(notes, d)
+		}
+	}
+	if n := ocrNote(w.text()); n != "" {
+		notes = append(notes, n)
+	}
+	return strings.Join(notes, "; ")
+}
@@ -0,0 +1,70 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package dict holds the word lists built into typo. Words in these lists
+// are known to be correct and are never reported. It also holds the
+// keyboard layouts used to rank suggestions.
+package dict // import "robpike.io/cmd/typo/dict"
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+)
+
+// W2006 is a list of common English words, one per line.
+//
+//go:embed w2006.txt
+var W2006 []byte
+
+// Bundled maps the names of languages to their built-in word lists.
+// Other languages' lists may be installed by the user; see typo's -lang flag.
+var Bundled = map[string][]byte{
+	"en": W2006,
+}
+
+// Confusables lists groups of words that are easily confused, one group
+// per line.
+//
+//go:embed confusables.txt
+var Confusables []byte
+
+// Variants lists pairs of British and American spellings of a word, one
+// pair per line.
+//
+//go:embed variants.txt
+var Variants []byte
+
+// Keyboards maps the names of keyboard layouts to their descriptions, which
+// are used to rank suggested corrections. The format is described in
+// qwerty.txt. Other layouts may be installed by the user; see typo's
+// -keyboard flag.
+var Keyboards = map[string][]byte{
+	"azerty": azerty,
+	"dvorak": dvorak,
+	"qwerty": qwerty,
+	"qwertz": qwertz,
+}
+
+var (
+	//go:embed azerty.txt
+	azerty []byte
+	//go:embed dvorak.txt
+	dvorak []byte
+	//go:embed qwerty.txt
+	qwerty []byte
+	//go:embed qwertz.txt
+	qwertz []byte
+)
+
+// Words returns the words of a list, which are separated by white space.
+func Words(list []byte) []string {
+	scanner := bufio.NewScanner(bytes.NewReader(list))
+	scanner.Split(bufio.ScanWords)
+	var words []string
+	for scanner.Scan() {
+		words = append(words, scanner.Text())
+	}
+	return words
+}
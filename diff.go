@@ -0,0 +1,186 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// With -diff or -git, typo reads a unified diff and reports only the words
+// on lines the diff adds or changes. The statistics still come from the
+// whole of each file, as they must for the scores to mean anything.
+
+// With -staged, typo checks the files staged for commit, reading their
+// contents from the git index rather than the working tree.
+
+var (
+	diffMode = flag.Bool("diff", false, "read a unified diff from standard input and report only words on added lines")
+	gitMode  = flag.Bool("git", false, "like -diff, but run git diff HEAD to get the diff")
+	staged   = flag.Bool("staged", false, "check the staged contents of the files staged for commit in git")
+)
+
+// changed records, for each file named in the diff, the lines it adds.
+// It is nil unless -diff or -git is set.
+var changed map[string]map[int]bool
+
+// changedFiles lists the files in the diff, in order.
+var changedFiles []string
+
+// readDiff sets changed and changedFiles from the diff selected by the flags.
+func readDiff() {
+	var r io.Reader = os.Stdin
+	name := "<stdin>"
+	if *gitMode {
+		out, err := git("diff", "--relative", "--no-color", "--no-ext-diff", "HEAD")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: git diff: %s\n", err)
+			os.Exit(2)
+		}
+		r = bytes.NewReader(out)
+		name = "<git diff>"
+	}
+	if err := parseDiff(r); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: reading %s: %s\n", name, err)
+		os.Exit(2)
+	}
+}
+
+// parseDiff parses a unified diff, recording the added lines of each file.
+// The counts in each hunk header say how many lines the hunk holds, so an
+// added line that looks like a header, such as "+++ x", is taken as text.
+func parseDiff(r io.Reader) error {
+	changed = make(map[string]map[int]bool)
+	var lines map[int]bool
+	lineNum := 0
+	oldLeft, newLeft := 0, 0 // Lines remaining in the hunk.
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				if lines != nil {
+					lines[lineNum] = true
+				}
+				lineNum++
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				oldLeft--
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file"
+			default: // Context, whose leading space may have been lost.
+				lineNum++
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			file := strings.TrimPrefix(line, "+++ ")
+			if i := strings.IndexByte(file, '\t'); i >= 0 {
+				file = file[:i] // Drop the time stamp.
+			}
+			if file == "/dev/null" {
+				lines = nil // Deleted file.
+				continue
+			}
+			file = diffPath(strings.TrimPrefix(file, "b/"))
+			lines = changed[file]
+			if lines == nil {
+				lines = make(map[int]bool)
+				changed[file] = lines
+				changedFiles = append(changedFiles, file)
+			}
+		case strings.HasPrefix(line, "@@ "):
+			// @@ -l,s +l,s @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+				return fmt.Errorf("bad hunk header %q", line)
+			}
+			_, olds, err := hunkRange(fields[1][1:])
+			if err != nil {
+				return fmt.Errorf("bad hunk header %q", line)
+			}
+			start, news, err := hunkRange(fields[2][1:])
+			if err != nil {
+				return fmt.Errorf("bad hunk header %q", line)
+			}
+			lineNum, oldLeft, newLeft = start, olds, news
+		}
+	}
+	return scanner.Err()
+}
+
+// hunkRange parses the range "l,s" or "l" of a hunk header, in which the
+// size s defaults to 1.
+func hunkRange(r string) (start, size int, err error) {
+	l, s, ok := strings.Cut(r, ",")
+	start, err = strconv.Atoi(l)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return start, 1, nil
+	}
+	size, err = strconv.Atoi(s)
+	return start, size, err
+}
+
+// diffPath returns the file name in the diff, or given as an argument, in
+// a form in which the two may be compared.
+func diffPath(file string) string {
+	return filepath.Clean(filepath.FromSlash(file))
+}
+
+// inDiff reports whether the word should be reported given -diff or -git.
+func inDiff(w *Word) bool {
+	if changed == nil {
+		return true
+	}
+	return changed[diffPath(w.file)][w.lineNum]
+}
+
+// addStaged adds the words of the staged contents of the files staged for commit.
+func addStaged() {
+	names, err := git("diff", "--cached", "--relative", "--name-only", "--diff-filter=ACMR", "-z")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: git diff: %s\n", err)
+		os.Exit(2)
+	}
+	for _, file := range strings.Split(string(names), "\x00") {
+		if file == "" {
+			continue
+		}
+		data, err := git("show", ":./"+file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: git show %s: %s\n", file, err)
+			continue
+		}
+		if isBinary(data) {
+			continue
+		}
+		// Show context from the index, not the working tree.
+		fileLines[file] = splitLines(string(data))
+		add(file, bytes.NewReader(data))
+	}
+}
+
+// git runs git with the arguments and returns its output.
+func git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
@@ -0,0 +1,218 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var diffTests = []struct {
+	name string
+	diff string
+	want map[string][]int // Added lines of each file.
+}{
+	{
+		name: "simple",
+		diff: `diff --git a/x.txt b/x.txt
+index 1234567..89abcde 100644
+--- a/x.txt
++++ b/x.txt
+@@ -1,3 +1,4 @@
+ one
+-two
++deux
++trois
+ four
+`,
+		want: map[string][]int{"x.txt": {2, 3}},
+	},
+	{
+		name: "added line that looks like a header",
+		diff: `--- a/x.txt
++++ b/x.txt
+@@ -1,2 +1,4 @@
+ one
++++ two
++@@ -9,9 +9,9 @@
+ three
+`,
+		want: map[string][]int{"x.txt": {2, 3}},
+	},
+	{
+		name: "removed line that looks like a header",
+		diff: `--- a/x.txt
++++ b/x.txt
+@@ -1,3 +1,2 @@
+ one
+--- two
++new
+-three
+`,
+		want: map[string][]int{"x.txt": {2}},
+	},
+	{
+		name: "counts omitted",
+		diff: `--- a/x.txt
++++ b/x.txt
+@@ -5 +5,2 @@
+-old
++new
+++++ not a header
+@@ -9 +10 @@
+-old
++new
+`,
+		want: map[string][]int{"x.txt": {5, 6, 10}},
+	},
+	{
+		name: "empty range",
+		diff: `--- /dev/null
++++ b/new.txt
+@@ -0,0 +1,2 @@
++first
++second
+`,
+		want: map[string][]int{"new.txt": {1, 2}},
+	},
+	{
+		name: "no newline at end of file",
+		diff: `--- a/x.txt
++++ b/x.txt
+@@ -1,2 +1,2 @@
+ one
+-two
+\ No newline at end of file
++two
+\ No newline at end of file
+`,
+		want: map[string][]int{"x.txt": {2}},
+	},
+	{
+		name: "context line without its space",
+		diff: `--- a/x.txt
++++ b/x.txt
+@@ -1,3 +1,3 @@
+ one
+
++three
+-four
+`,
+		want: map[string][]int{"x.txt": {3}},
+	},
+	{
+		name: "several hunks and files",
+		diff: `--- a/x.txt
++++ b/x.txt
+@@ -1,1 +1,2 @@
+ one
++two
+@@ -10,2 +11,2 @@
+-ten
++eleven
+ twelve
+--- a/y.txt	2013-01-01 00:00:00
++++ b/y.txt	2013-01-02 00:00:00
+@@ -1 +1 @@
+-a
++b
+`,
+		want: map[string][]int{"x.txt": {2, 11}, "y.txt": {1}},
+	},
+	{
+		name: "deleted file",
+		diff: `--- a/gone.txt
++++ /dev/null
+@@ -1,2 +0,0 @@
+-one
+-two
+`,
+		want: map[string][]int{},
+	},
+	{
+		name: "paths to clean",
+		diff: `--- a/./dir//x.txt
++++ b/./dir//x.txt
+@@ -1 +1 @@
+-a
++b
+--- y.txt
++++ ./z.txt
+@@ -1 +1 @@
+-a
++b
+`,
+		want: map[string][]int{"dir/x.txt": {1}, "z.txt": {1}},
+	},
+}
+
+func TestParseDiff(t *testing.T) {
+	defer func() { changed, changedFiles = nil, nil }()
+	for _, test := range diffTests {
+		if err := parseDiff(strings.NewReader(test.diff)); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got := make(map[string][]int)
+		for file, lines := range changed {
+			var nums []int
+			for n := range lines {
+				nums = append(nums, n)
+			}
+			slices.Sort(nums)
+			got[filepath.ToSlash(file)] = nums
+		}
+		for file, lines := range test.want {
+			if !slices.Equal(got[file], lines) {
+				t.Errorf("%s: %s: got lines %v; want %v", test.name, file, got[file], lines)
+			}
+		}
+		for file := range got {
+			if _, ok := test.want[file]; !ok {
+				t.Errorf("%s: unexpected file %s", test.name, file)
+			}
+		}
+	}
+}
+
+func TestParseDiffErrors(t *testing.T) {
+	tests := []string{
+		"@@ -1,2 @@\n",
+		"@@ 1,2 +1,2 @@\n",
+		"@@ -x,2 +1,2 @@\n",
+		"@@ -1,2 +1,y @@\n",
+	}
+	defer func() { changed, changedFiles = nil, nil }()
+	for _, diff := range tests {
+		if err := parseDiff(strings.NewReader(diff)); err == nil {
+			t.Errorf("%q: no error", diff)
+		}
+	}
+}
+
+func TestInDiff(t *testing.T) {
+	defer func() { changed, changedFiles = nil, nil }()
+	if err := parseDiff(strings.NewReader(diffTests[0].diff)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		file string
+		line int
+		want bool
+	}{
+		{"x.txt", 2, true},
+		{"./x.txt", 3, true},
+		{"x.txt", 1, false},
+		{"y.txt", 2, false},
+	}
+	for _, test := range tests {
+		w := &Word{file: test.file, lineNum: test.line}
+		if got := inDiff(w); got != test.want {
+			t.Errorf("inDiff(%s:%d) = %t; want %t", test.file, test.line, got, test.want)
+		}
+	}
+}
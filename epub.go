@@ -0,0 +1,177 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// An EPUB book is a zip archive of XHTML documents. The package file named
+// by META-INF/container.xml lists them in reading order, its spine. Each is
+// scanned with its markup and its script, style, pre, and code elements
+// blanked out, as by -html, and its words are reported as
+// book.epub!OEBPS/chapter3.xhtml:line:col.
+
+// addEPUB adds the words of the file if it is an EPUB book, and reports
+// whether it did.
+func addEPUB(file string) bool {
+	if strings.ToLower(filepath.Ext(file)) != ".epub" {
+		return false
+	}
+	z, err := zip.OpenReader(file)
+	if err != nil {
+		return false // Let the caller report the error.
+	}
+	defer z.Close()
+	spine, err := epubSpine(&z.Reader)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s: %s\n", file, err)
+		failed = true
+		return true
+	}
+	for _, name := range spine {
+		data, err := readZipFile(&z.Reader, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s!%s: %s\n", file, name, err)
+			failed = true
+			continue
+		}
+		add(file+"!"+name, bytes.NewReader(stripHTML(data)))
+	}
+	return true
+}
+
+// epubSpine returns the names, within the archive, of the documents of
+// the book in reading order.
+func epubSpine(z *zip.Reader) ([]string, error) {
+	data, err := readZipFile(z, "META-INF/container.xml")
+	if err != nil {
+		return nil, err
+	}
+	var container struct {
+		Rootfiles []struct {
+			Path string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(data, &container); err != nil {
+		return nil, fmt.Errorf("container.xml: %s", err)
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("container.xml: no package file")
+	}
+	opf := container.Rootfiles[0].Path
+	data, err = readZipFile(z, opf)
+	if err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Items []struct {
+			ID   string `xml:"id,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := xml.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("%s: %s", opf, err)
+	}
+	hrefs := make(map[string]string)
+	for _, item := range pkg.Items {
+		hrefs[item.ID] = item.Href
+	}
+	var names []string
+	for _, ref := range pkg.Spine {
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		if h, err := url.PathUnescape(href); err == nil {
+			href = h
+		}
+		names = append(names, path.Join(path.Dir(opf), href))
+	}
+	return names, nil
+}
+
+// readZipFile returns the contents of the named file in the archive.
+func readZipFile(z *zip.Reader, name string) ([]byte, error) {
+	f, err := z.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// stripHTML blanks out the script, style, pre, and code elements of the
+// HTML, as htmlFilter does, and then the rest of its markup.
+func stripHTML(data []byte) []byte {
+	filter := htmlFilter()
+	var b bytes.Buffer
+	for i, line := range strings.Split(string(data), "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(filter(line))
+	}
+	return stripMarkup(b.Bytes())
+}
+
+// stripMarkup replaces the tags, comments, and entities of the HTML with
+// spaces, leaving newlines alone so the lines and columns of the words
+// that remain are unchanged.
+func stripMarkup(data []byte) []byte {
+	text := make([]byte, len(data))
+	copy(text, data)
+	blank := func(i, j int) {
+		for ; i < j; i++ {
+			if text[i] != '\n' && text[i] != '\r' {
+				text[i] = ' '
+			}
+		}
+	}
+	for i := 0; i < len(text); {
+		var end int
+		switch {
+		case bytes.HasPrefix(text[i:], []byte("<!--")):
+			end = bytes.Index(text[i:], []byte("-->"))
+			if end >= 0 {
+				end += len("-->")
+			}
+		case text[i] == '<':
+			end = bytes.IndexByte(text[i:], '>')
+			if end >= 0 {
+				end++
+			}
+		case text[i] == '&':
+			end = bytes.IndexByte(text[i:], ';')
+			if end > 10 || bytes.ContainsAny(text[i:i+max(end, 0)], " \t\n") {
+				end = -1 // A bare ampersand.
+			} else if end >= 0 {
+				end++
+			}
+		default:
+			i++
+			continue
+		}
+		if end < 0 {
+			i++
+			continue
+		}
+		blank(i, i+end)
+		i += end
+	}
+	return text
+}
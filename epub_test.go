@@ -0,0 +1,26 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{"<p>Some &amp; text</p>", "   Some       text    "},
+		{"a<script>var xq = 1;</script>b", "a                            b"},
+		{"<style>\np { colr: red }\n</style>\nword", "       \n               \n        \nword"},
+		{"<PRE>x := y</PRE> z", "                  z"},
+		{"<code>fmt.Println</code> and <b>more</b>", "                         and    more    "},
+		{"<!-- a\ncomment -->text", "      \n           text"},
+	}
+	for _, test := range tests {
+		if got := string(stripHTML([]byte(test.html))); got != test.want {
+			t.Errorf("stripHTML(%q) = %q; want %q", test.html, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"robpike.io/cmd/typo/trigram"
+)
+
+var explainWords = flag.String("explain", "", "instead of reporting, show how each of the comma-separated `words` is scored against the input")
+
+// explain prints, for each word named by -explain, each of its trigrams xyz
+// with the counts n(xy), n(yz), and n(xyz) from the statistics of the input
+// and its index i(T), followed by how the indices combine into the word's
+// score under -zero and -combine. Unless the statistics
+// are a reference, as with -corpus, one is subtracted from each count before
+// computing i(T), to remove the effect of the word itself; a word that does
+// not appear in the input is added to it first, so it is scored as if it did.
+func explain() {
+	for i, word := range strings.Split(*explainWords, ",") {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		f := form(word)
+		t := pooledTable()
+		if corpus == nil && !inInput(f) {
+			t.Add(f)
+		}
+		known := ""
+		if isKnown(fold(word)) {
+			known = " (known, so never reported)"
+		}
+		fmt.Fprintf(out, "%s: score %.1f%s\n", word, t.Score(f), known)
+		tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(tw, "\ttrigram\tn(xy)\tn(yz)\tn(xyz)\ti(T)\t\n")
+		trigram.Scan(f, func(tri trigram.Trigram) {
+			fmt.Fprintf(tw, "\t%s\t%d\t%d\t%d\t%.3f\t\n", string(tri[:]),
+				t.DiCount(trigram.Digram{tri[0], tri[1]}),
+				t.DiCount(trigram.Digram{tri[1], tri[2]}),
+				t.TriCount(tri),
+				t.TriScore(tri))
+		})
+		tw.Flush()
+		explainScore(t, f)
+	}
+}
+
+// explainScore prints how the indices of the trigrams of f combine into its
+// score in the table.
+func explainScore(t *trigram.Table, f string) {
+	c, unseen := t.Combined(f)
+	lo, hi, ok := t.Scale()
+	of := "i(T)"
+	if !ok {
+		of = "|i(T)|"
+	}
+	fmt.Fprintf(out, "-zero=%s -combine=%s: %s of %s = %.3f; ", *zeroCounts, *combine, *combine, of, c)
+	switch {
+	case zeroHandling() == trigram.Skip && combination() == trigram.Max && unseen:
+		fmt.Fprintf(out, "a trigram has a zero count, so score = 100\n")
+	case ok:
+		fmt.Fprintf(out, "score = max(10 + 10*(%.3f - %.1f)/(%.1f - %.1f), 0) = %.1f\n", c, lo, hi, lo, t.Score(f))
+	case c == 0:
+		fmt.Fprintf(out, "score = 10/%.3f, so 100\n", c)
+	default:
+		fmt.Fprintf(out, "score = 10/%.3f = %.1f\n", c, t.Score(f))
+	}
+}
+
+// inInput reports whether a word of the input has the form f.
+func inInput(f string) bool {
+	for _, w := range words {
+		if !w.ignore && form(w.text()) == f {
+			return true
+		}
+	}
+	return false
+}
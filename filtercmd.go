@@ -0,0 +1,89 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// With -filter-cmd, each file is given on standard input to a command,
+// such as 'pandoc -t plain', whose standard output is scanned in its place.
+// The command's words are separated by white space and may be quoted with
+// ' or ", as in a shell but with no escapes or expansions; the name of the file
+// is in the environment variable TYPO_FILE. Words are reported at their
+// locations in the output, which are those in the file only if the command
+// keeps them, as typo's own filters do by blanking out what they remove.
+// So that a repository's configuration file cannot run commands, the flag
+// may be given only on the command line.
+
+var filterCmd = flag.String("filter-cmd", "", "scan the output of the `command` run with each file on its standard input and its name in $TYPO_FILE")
+
+func init() {
+	addFilter(filter{name: "filter-cmd", on: func() bool { return *filterCmd != "" }})
+}
+
+// addFiltered adds the words of the output of -filter-cmd run on the file,
+// if it is set, and reports whether it did.
+func addFiltered(file string) bool {
+	args := commandArgs(*filterCmd)
+	if len(args) == 0 {
+		return false
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return false // Let the caller report the error.
+	}
+	defer f.Close()
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = f
+	cmd.Env = append(os.Environ(), "TYPO_FILE="+file)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	text, err := cmd.Output()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s %s: %v: %s\n", args[0], file, err, bytes.TrimSpace(stderr.Bytes()))
+		failed = true
+		return true
+	}
+	add(file, bytes.NewReader(text))
+	return true
+}
+
+// commandArgs splits the command into its words, which are separated by
+// white space unless it is in quotes.
+func commandArgs(s string) []string {
+	var args []string
+	var b strings.Builder
+	inWord := false
+	quote := rune(0)
+	for _, c := range s {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			b.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				args = append(args, b.String())
+				b.Reset()
+				inWord = false
+			}
+		default:
+			b.WriteRune(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		args = append(args, b.String())
+	}
+	return args
+}
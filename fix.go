@@ -0,0 +1,241 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	fixMode = flag.Bool("fix", false, "step through unlikely words interactively, correcting them in place")
+	backup  = flag.Bool("backup", false, "with -fix, save each file as file.orig before rewriting it")
+)
+
+// An edit replaces one occurrence of a word.
+type edit struct {
+	lineNum int
+	byteNum int
+	old     string
+	new     string
+}
+
+// fix presents each word in the list in context, with suggested corrections,
+// and asks what to do with it. Answers are read from standard input, so the
+// files must be named as arguments. An accepted correction is applied to
+// every occurrence of the word. The files are rewritten once all the words
+// have been seen or the user quits.
+func fix(list []*Word) {
+	for _, w := range list {
+		if w.file == "<stdin>" {
+			fmt.Fprintf(os.Stderr, "typo: -fix needs files named as arguments\n")
+			os.Exit(2)
+		}
+	}
+	edits := make(map[string][]edit)
+	in := bufio.NewReader(os.Stdin)
+Loop:
+	for i, w := range list {
+		if i >= *nTypos {
+			break
+		}
+		fmt.Printf("\n%s\n", w)
+		showLine(w)
+		sugg := suggest(w.text(), 9)
+		for j, s := range sugg {
+			fmt.Printf("  %d) %s\n", j+1, s)
+		}
+		for {
+			learnHelp := ""
+			if *learnMode {
+				learnHelp = ", a add to dictionary"
+			}
+			fmt.Printf("[1-%d] replace, e edit%s, s skip, q quit: ", len(sugg), learnHelp)
+			answer, err := in.ReadString('\n')
+			if err == io.EOF && answer == "" {
+				fmt.Println()
+				break Loop
+			}
+			answer = strings.TrimSpace(answer)
+			repl := ""
+			switch answer {
+			case "", "s":
+				continue Loop
+			case "q":
+				break Loop
+			case "a":
+				if !*learnMode {
+					continue
+				}
+				if err := learn([]string{w.text()}); err != nil {
+					fmt.Fprintf(os.Stderr, "typo: learning %s: %s\n", w.text(), err)
+				}
+				continue Loop
+			case "e":
+				fmt.Printf("replace %s with: ", w.text())
+				answer, _ = in.ReadString('\n')
+				repl = strings.TrimSpace(answer)
+			default:
+				n, err := strconv.Atoi(answer)
+				if err != nil || n < 1 || n > len(sugg) {
+					continue
+				}
+				repl = sugg[n-1]
+			}
+			if repl == "" || repl == w.text() {
+				continue Loop
+			}
+			addEdits(edits, w.text(), repl)
+			continue Loop
+		}
+	}
+	applyEdits(edits)
+}
+
+// addEdits adds to edits the replacement of every occurrence of the word.
+func addEdits(edits map[string][]edit, word, repl string) {
+	for _, o := range words {
+		if o.text() == word {
+			edits[o.file] = append(edits[o.file], edit{o.lineNum, o.byteNum, o.text(), repl})
+		}
+	}
+}
+
+// applyEdits rewrites the files as directed, exiting on failure.
+func applyEdits(edits map[string][]edit) {
+	files := make([]string, 0, len(edits))
+	for file := range edits {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	failed := false
+	for _, file := range files {
+		if err := rewrite(file, edits[file]); err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(2)
+	}
+}
+
+// showLine prints the line holding the word, with the word marked beneath it.
+func showLine(w *Word) {
+	line := fileLine(w.file, w.lineNum)
+	i := w.byteNum - 1
+	if i+len(w.text()) > len(line) || line[i:i+len(w.text())] != w.text() {
+		return // The file has changed, or the line is not as read.
+	}
+	fmt.Printf("  %s\n  ", line)
+	// Copy tabs so the marker lines up.
+	for _, c := range line[:i] {
+		if c == '\t' {
+			fmt.Print("\t")
+		} else {
+			fmt.Print(" ")
+		}
+	}
+	fmt.Println(strings.Repeat("^", len([]rune(w.text()))))
+}
+
+// fileLines caches the lines of the files shown by fileLine.
+var fileLines = make(map[string][]string)
+
+// fileLine returns the text of the line, decoded as the file was when it
+// was read, so byte offsets in it match those of the words, or the empty
+// string if it cannot be read.
+func fileLine(file string, lineNum int) string {
+	lines, ok := fileLines[file]
+	if !ok {
+		if text, err := readDecoded(file); err == nil {
+			lines = splitLines(text)
+		}
+		fileLines[file] = lines
+	}
+	if lineNum < 1 || lineNum > len(lines) {
+		return ""
+	}
+	return lines[lineNum-1]
+}
+
+// readDecoded returns the text of the file, decoded as by read.
+func readDecoded(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	r, err := decode(f)
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(r)
+	return string(data), err
+}
+
+// rewrite applies the edits to the file. The offsets of the edits are in
+// the text as decoded by read, so the edits are applied to that text, which
+// is then encoded as the file was. Compressed files are not rewritten.
+func rewrite(file string, edits []edit) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if compressed(data) {
+		return fmt.Errorf("%s: compressed; not rewriting file", file)
+	}
+	text := data
+	e := textEncoding(data[:min(len(data), 512)])
+	if e != nil {
+		text, err = e.NewDecoder().Bytes(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v; not rewriting file", file, err)
+		}
+	}
+	// Work from the end so earlier offsets stay valid.
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].lineNum != edits[j].lineNum {
+			return edits[i].lineNum > edits[j].lineNum
+		}
+		return edits[i].byteNum > edits[j].byteNum
+	})
+	lines := splitLinesAfter(text)
+	for _, e := range edits {
+		if e.lineNum > len(lines) {
+			continue
+		}
+		line := lines[e.lineNum-1]
+		i := e.byteNum - 1
+		if i > len(line) || !bytes.HasPrefix(line[i:], []byte(e.old)) {
+			return fmt.Errorf("%s:%d: %q has changed; not rewriting file", file, e.lineNum, e.old)
+		}
+		lines[e.lineNum-1] = append(append(line[:i:i], e.new...), line[i+len(e.old):]...)
+	}
+	text = bytes.Join(lines, nil)
+	if e != nil {
+		text, err = e.NewEncoder().Bytes(text)
+		if err != nil {
+			return fmt.Errorf("%s: %v; not rewriting file", file, err)
+		}
+	}
+	if *backup {
+		if err := os.WriteFile(file+".orig", data, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(file, text, info.Mode().Perm())
+}
@@ -0,0 +1,121 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// utf16 returns the text in UTF-16 in the byte order, with a byte order
+// mark if bom is set.
+func utf16(text string, order unicode.Endianness, bom bool) string {
+	policy := unicode.IgnoreBOM
+	if bom {
+		policy = unicode.UseBOM
+	}
+	data, err := unicode.UTF16(order, policy).NewEncoder().String(text)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding encoding.Encoding // As set by -encoding.
+		file     string
+		edits    []edit // Offsets are in the decoded text.
+		want     string
+	}{
+		{
+			name:  "UTF-8",
+			file:  "the cat\nteh dog\n",
+			edits: []edit{{2, 1, "teh", "the"}},
+			want:  "the cat\nthe dog\n",
+		},
+		{
+			name:  "byte order mark",
+			file:  "\uFEFFteh cat\nsat on teh mat\n",
+			edits: []edit{{1, 1, "teh", "the"}, {2, 8, "teh", "the"}},
+			want:  "\uFEFFthe cat\nsat on the mat\n",
+		},
+		{
+			name:  "UTF-16 with byte order mark",
+			file:  utf16("teh café\n", unicode.BigEndian, true),
+			edits: []edit{{1, 1, "teh", "the"}},
+			want:  utf16("the café\n", unicode.BigEndian, true),
+		},
+		{
+			name:  "UTF-16 without byte order mark",
+			file:  utf16("café teh\r\nteh\r\n", unicode.LittleEndian, false),
+			edits: []edit{{1, 7, "teh", "the"}, {2, 1, "teh", "the"}},
+			want:  utf16("café the\r\nthe\r\n", unicode.LittleEndian, false),
+		},
+		{
+			name:     "-encoding",
+			encoding: charmap.ISO8859_1,
+			file:     "caf\xe9 teh\n",
+			edits:    []edit{{1, 7, "teh", "thé"}},
+			want:     "caf\xe9 th\xe9\n",
+		},
+	}
+	defer func() { inputEncoding = nil }()
+	for _, test := range tests {
+		inputEncoding = test.encoding
+		file := filepath.Join(t.TempDir(), "test.txt")
+		if err := os.WriteFile(file, []byte(test.file), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := rewrite(file, test.edits); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.want {
+			t.Errorf("%s: got %q; want %q", test.name, data, test.want)
+		}
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding encoding.Encoding
+		file     string
+		edits    []edit
+	}{
+		{"changed", nil, "\uFEFFthe cat\n", []edit{{1, 1, "teh", "the"}}},
+		{"compressed", nil, "\x1f\x8b teh", []edit{{1, 4, "teh", "the"}}},
+		{"not encodable", charmap.ISO8859_1, "teh\n", []edit{{1, 1, "teh", "θe"}}},
+	}
+	defer func() { inputEncoding = nil }()
+	for _, test := range tests {
+		inputEncoding = test.encoding
+		file := filepath.Join(t.TempDir(), "test.txt")
+		if err := os.WriteFile(file, []byte(test.file), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := rewrite(file, test.edits); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.file {
+			t.Errorf("%s: file rewritten as %q", test.name, data)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+)
+
+var (
+	freqMode    = flag.Bool("freq", false, "instead of reporting, print the number of uses of each distinct word, most frequent first")
+	freqUnknown = flag.Bool("freq-unknown", false, "with -freq, leave out known words")
+)
+
+// printFreq prints the frequency table of the words of the input, folded
+// to lower case. Ignored words are left out.
+func printFreq() {
+	count := make(map[string]int)
+	for _, w := range words {
+		if w.ignore || *freqUnknown && isKnown(w.lower()) {
+			continue
+		}
+		count[w.lower()]++
+	}
+	list := make([]string, 0, len(count))
+	for w := range count {
+		list = append(list, w)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if count[list[i]] != count[list[j]] {
+			return count[list[i]] > count[list[j]]
+		}
+		return list[i] < list[j]
+	})
+	for _, w := range list {
+		fmt.Fprintf(out, "%7d %s\n", count[w], w)
+	}
+}
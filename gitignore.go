@@ -0,0 +1,154 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var noGitignore = flag.Bool("no-gitignore", false, "when walking directories, scan the files that .gitignore files exclude, and .git directories")
+
+// An ignoreRule is a pattern from a .gitignore file.
+type ignoreRule struct {
+	dir     string // The directory holding the .gitignore file.
+	re      *regexp.Regexp
+	negate  bool // The pattern began with !; matching files are not ignored.
+	dirOnly bool // The pattern ended with /; it matches only directories.
+	base    bool // The pattern has no slash; it matches the name at any depth.
+}
+
+// gitignore holds the rules of the .gitignore files seen in a walk.
+type gitignore struct {
+	rules []ignoreRule
+}
+
+// newGitignore returns a gitignore holding the rules of the .gitignore files
+// in the directories enclosing the directory, up to the top of its git
+// repository, if it is in one.
+func newGitignore(dir string) *gitignore {
+	g := new(gitignore)
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return g
+	}
+	var dirs []string
+	for d := filepath.Dir(abs); ; d = filepath.Dir(d) {
+		dirs = append(dirs, d)
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			break
+		}
+		if filepath.Dir(d) == d {
+			dirs = nil // Not in a repository.
+			break
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		g.load(dirs[i])
+	}
+	return g
+}
+
+// load adds the rules of the .gitignore file in the directory, if any.
+func (g *gitignore) load(dir string) {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := ignoreRule{dir: abs}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		r.base = !strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		re, err := regexp.Compile("^" + globRegexp(line) + "$")
+		if err != nil {
+			continue
+		}
+		r.re = re
+		g.rules = append(g.rules, r)
+	}
+}
+
+// globRegexp returns the regular expression for the .gitignore pattern.
+func globRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			switch {
+			case strings.HasPrefix(glob[i:], "**/"):
+				b.WriteString("(.*/)?")
+				i += 2
+			case strings.HasPrefix(glob[i:], "**"):
+				b.WriteString(".*")
+				i++
+			default:
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			j := strings.IndexByte(glob[i:], ']')
+			if j < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += j
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// ignored reports whether the file, with the absolute path, is ignored.
+// As in git, the last rule that matches decides.
+func (g *gitignore) ignored(path string, isDir bool) bool {
+	ignore := false
+	for _, r := range g.rules {
+		if r.dirOnly && !isDir || !strings.HasPrefix(path, r.dir+string(filepath.Separator)) {
+			continue
+		}
+		rel := filepath.ToSlash(path[len(r.dir)+1:])
+		if r.base {
+			rel = rel[strings.LastIndexByte(rel, '/')+1:]
+		}
+		if r.re.MatchString(rel) {
+			ignore = !r.negate
+		}
+	}
+	return ignore
+}
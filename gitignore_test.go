@@ -0,0 +1,108 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*.o", "x.o", true},
+		{"*.o", "dir/x.o", false},
+		{"*.o", "x.oo", false},
+		{"a?c", "abc", true},
+		{"a?c", "a/c", false},
+		{"**/foo", "foo", true},
+		{"**/foo", "a/b/foo", true},
+		{"**/foo", "a/xfoo", false},
+		{"a/**", "a/b/c", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"[abc].txt", "b.txt", true},
+		{"[abc].txt", "d.txt", false},
+		{"[!abc].txt", "d.txt", true},
+		{"[!abc].txt", "a.txt", false},
+		{"[a-c]x", "bx", true},
+		{"a[b", "a[b", true},
+		{`\*.txt`, "*.txt", true},
+		{`\*.txt`, "a.txt", false},
+		{`\#x`, "#x", true},
+		{"a.b", "axb", false},
+		{"a+b", "a+b", true},
+	}
+	for _, test := range tests {
+		re, err := regexp.Compile("^" + globRegexp(test.glob) + "$")
+		if err != nil {
+			t.Errorf("%q: %v", test.glob, err)
+			continue
+		}
+		if got := re.MatchString(test.path); got != test.match {
+			t.Errorf("%q on %q: got %t; want %t", test.glob, test.path, got, test.match)
+		}
+	}
+}
+
+func TestGitignore(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, text string) {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(name)), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), []byte(text), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".gitignore", `# Build output.
+*.o
+/top
+build/
+!keep.o
+doc/*.html
+trailing   
+`)
+	write("sub/.gitignore", "*.txt\n!important.txt\n")
+	g := new(gitignore)
+	g.load(root)
+	g.load(filepath.Join(root, "sub"))
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"x.o", false, true},
+		{"a/b/x.o", false, true},
+		{"keep.o", false, false},
+		{"a/keep.o", false, false},
+		{"top", false, true},
+		{"a/top", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"a/build", true, true},
+		{"doc/x.html", false, true},
+		{"doc/a/x.html", false, false},
+		{"a/doc/x.html", false, false},
+		{"trailing", false, true},
+		{"x.txt", false, false},
+		{"sub/x.txt", false, true},
+		{"sub/a/x.txt", false, true},
+		{"sub/important.txt", false, false},
+		{"sub/x.o", false, true},
+		{"# Build output.", false, false},
+	}
+	for _, test := range tests {
+		path := filepath.Join(root, filepath.FromSlash(test.path))
+		if got := g.ignored(path, test.isDir); got != test.want {
+			t.Errorf("ignored(%q, %t) = %t; want %t", test.path, test.isDir, got, test.want)
+		}
+	}
+}
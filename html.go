@@ -0,0 +1,129 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+)
+
+// An htmlFile holds the findings for one file in the HTML report.
+type htmlFile struct {
+	Name     string
+	Findings []htmlFinding
+}
+
+// An htmlFinding is a row of the HTML report. The excerpt is the line
+// holding the word, split around it.
+type htmlFinding struct {
+	Kind     string
+	Severity string
+	Line     int
+	Col      int
+	Score    int
+	Word     string
+	Before   string
+	After    string
+}
+
+// writeHTML writes a standalone HTML page listing the findings, grouped by file.
+func writeHTML(reps, list []*Word) {
+	files := make(map[string]*htmlFile)
+	var names []string
+	addRow := func(w *Word, kind string) {
+		f := files[w.file]
+		if f == nil {
+			f = &htmlFile{Name: w.file}
+			files[w.file] = f
+			names = append(names, w.file)
+		}
+		row := htmlFinding{
+			Kind:     kind,
+			Severity: severity(w, kind),
+			Line:     w.lineNum,
+			Col:      w.col,
+			Score:    int(w.score),
+			Word:     w.text(),
+		}
+		line := fileLine(w.file, w.lineNum)
+		i := w.byteNum - 1
+		if i+len(w.text()) <= len(line) && line[i:i+len(w.text())] == w.text() {
+			row.Before = line[:i]
+			row.After = line[i+len(w.text()):]
+		}
+		f.Findings = append(f.Findings, row)
+	}
+	for _, w := range reps {
+		addRow(w, "repeat")
+	}
+	for _, w := range list {
+		addRow(w, "typo")
+	}
+	sort.Strings(names)
+	var data []*htmlFile
+	for _, name := range names {
+		f := files[name]
+		sort.SliceStable(f.Findings, func(i, j int) bool {
+			a, b := f.Findings[i], f.Findings[j]
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Col < b.Col
+		})
+		data = append(data, f)
+	}
+	if err := htmlTemplate.Execute(out, data); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: writing HTML: %s\n", err)
+		os.Exit(2)
+	}
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>typo report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { text-align: left; padding: 0.2em 0.8em; border-bottom: 1px solid #ddd; }
+th { cursor: pointer; background: #f4f4f4; }
+td.num { text-align: right; }
+td.excerpt { font-family: monospace; white-space: pre-wrap; }
+mark { background: #fd6; }
+tr.repeat td { color: #666; }
+</style>
+<script>
+// sortTable sorts the rows of the table by the numeric column, alternating direction.
+function sortTable(th, col) {
+	var table = th.closest("table");
+	var body = table.tBodies[0];
+	var rows = Array.from(body.rows);
+	var dir = table.dataset.dir === "down" ? 1 : -1;
+	table.dataset.dir = dir === 1 ? "up" : "down";
+	rows.sort(function(a, b) {
+		return dir * (Number(a.cells[col].textContent) - Number(b.cells[col].textContent));
+	});
+	rows.forEach(function(r) { body.appendChild(r); });
+}
+</script>
+</head>
+<body>
+<h1>typo report</h1>
+{{if not .}}<p>No findings.</p>{{end}}
+{{range .}}
+<h2>{{.Name}}</h2>
+<table>
+<thead><tr><th onclick="sortTable(this, 0)">Line</th><th>Col</th><th onclick="sortTable(this, 2)">Score</th><th>Word</th><th>Context</th></tr></thead>
+<tbody>
+{{range .Findings}}<tr class="{{.Kind}} {{.Severity}}"><td class="num">{{.Line}}</td><td class="num">{{.Col}}</td><td class="num">{{if eq .Kind "repeat"}}0{{else}}{{.Score}}{{end}}</td><td>{{.Word}}{{if eq .Kind "repeat"}} (repeats){{end}}</td><td class="excerpt">{{.Before}}<mark>{{.Word}}</mark>{{.After}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,62 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"regexp"
+	"strings"
+)
+
+var htmlPre = flag.Bool("html-pre", false, "with -html, check the text of <pre> elements")
+
+func init() {
+	addFilter(filter{name: "html", on: func() bool { return *filterHTML }, markup: htmlFilter})
+}
+
+var (
+	// The start of an element whose contents are not prose.
+	htmlSkipStart = regexp.MustCompile(`(?i)<(script|style|pre|code)(?:[\s>/]|$)`)
+	// The end tags of those elements.
+	htmlSkipEnd = map[string]*regexp.Regexp{
+		"script": regexp.MustCompile(`(?i)</script\s*>`),
+		"style":  regexp.MustCompile(`(?i)</style\s*>`),
+		"pre":    regexp.MustCompile(`(?i)</pre\s*>`),
+		"code":   regexp.MustCompile(`(?i)</code\s*>`),
+	}
+)
+
+// htmlFilter returns a filter that blanks out the script, style, pre, and
+// code elements of HTML, which may span lines. With -html-pre, the
+// contents of pre elements are kept.
+func htmlFilter() lineFilter {
+	skip := "" // The element being skipped.
+	return func(line string) string {
+		pos := 0
+		for pos < len(line) {
+			if skip == "" {
+				m := htmlSkipStart.FindStringSubmatchIndex(line[pos:])
+				if m == nil {
+					break
+				}
+				name := strings.ToLower(line[pos+m[2] : pos+m[3]])
+				if name == "pre" && *htmlPre {
+					pos += m[3]
+					continue
+				}
+				skip = name
+				pos += m[0]
+			}
+			m := htmlSkipEnd[skip].FindStringIndex(line[pos:])
+			if m == nil {
+				return blank(line, pos, len(line))
+			}
+			line = blank(line, pos, pos+m[1])
+			pos += m[1]
+			skip = ""
+		}
+		return line
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/ianaindex"
+)
+
+// A reader for hunspell (and myspell) dictionaries. A dictionary is a pair
+// of files: foo.dic lists stems, each with a set of flags, and foo.aff
+// defines the prefix and suffix rules the flags name. readHunspell expands
+// every stem by its rules, including combined prefix and suffix forms, to
+// produce a plain list of words. Other affix file features, such as
+// compounding and replacement tables, are ignored.
+
+// An affixRule is a single PFX or SFX rule.
+type affixRule struct {
+	strip string
+	add   string
+	cond  []charClass // Must match the start (prefix) or end (suffix) of the stem.
+}
+
+// A charClass is one element of a rule's condition.
+type charClass struct {
+	any    bool   // ".": any character.
+	negate bool   // "[^...]"
+	runes  string // The characters in the class.
+}
+
+func (c charClass) match(r rune) bool {
+	if c.any {
+		return true
+	}
+	return strings.ContainsRune(c.runes, r) != c.negate
+}
+
+// An affix is the set of rules named by a flag.
+type affix struct {
+	prefix bool
+	cross  bool // May combine with affixes of the other kind.
+	rules  []affixRule
+}
+
+// An affixFile holds what we need from a .aff file.
+type affixFile struct {
+	flagType string // "", "long", "num", or "UTF-8".
+	decode   func(string) string
+	affixes  map[string]*affix
+}
+
+// readHunspell returns the words of the hunspell dictionary in the .dic file,
+// expanded by the rules of the .aff file beside it.
+func readHunspell(dicFile string) ([]string, error) {
+	aff, err := readAffixFile(strings.TrimSuffix(dicFile, ".dic") + ".aff")
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(dicFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var words []string
+	scanner := bufio.NewScanner(f)
+	first := true
+	for scanner.Scan() {
+		line := aff.decode(scanner.Text())
+		if first {
+			// The first line is the approximate number of entries.
+			first = false
+			if _, err := strconv.Atoi(strings.TrimSpace(line)); err == nil {
+				continue
+			}
+		}
+		// Morphological fields follow white space.
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		stem, flags := line, ""
+		if i := strings.Index(line, "/"); i > 0 {
+			stem, flags = line[:i], line[i+1:]
+		}
+		words = aff.expand(words, stem, aff.flags(flags))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %s", dicFile, err)
+	}
+	return words, nil
+}
+
+// readAffixFile parses the affix rules of a .aff file.
+func readAffixFile(file string) (*affixFile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	aff := &affixFile{
+		decode:  func(s string) string { return s },
+		affixes: make(map[string]*affix),
+	}
+	// The encoding must be known before anything else is read.
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		fields := strings.Fields(string(line))
+		if len(fields) >= 2 && fields[0] == "SET" {
+			name := fields[1]
+			if strings.HasPrefix(name, "ISO8859") {
+				name = "ISO-8859" + strings.TrimPrefix(name, "ISO8859")
+			}
+			enc, err := ianaindex.IANA.Encoding(name)
+			if err == nil && enc != nil {
+				dec := enc.NewDecoder()
+				aff.decode = func(s string) string {
+					t, err := dec.String(s)
+					if err != nil {
+						return s
+					}
+					return t
+				}
+			}
+			break
+		}
+	}
+	for lineNum, line := range bytes.Split(data, []byte("\n")) {
+		fields := strings.Fields(aff.decode(string(line)))
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "FLAG":
+			aff.flagType = fields[1]
+		case "PFX", "SFX":
+			a := aff.affixes[fields[1]]
+			if a == nil {
+				// Header: PFX flag cross_product count
+				if len(fields) < 4 {
+					return nil, fmt.Errorf("%s:%d: bad affix header", file, lineNum+1)
+				}
+				aff.affixes[fields[1]] = &affix{
+					prefix: fields[0] == "PFX",
+					cross:  fields[2] == "Y",
+				}
+				continue
+			}
+			// Rule: PFX flag strip add condition
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("%s:%d: bad affix rule", file, lineNum+1)
+			}
+			rule := affixRule{
+				strip: zero(fields[2]),
+				add:   zero(fields[3]),
+			}
+			if i := strings.Index(rule.add, "/"); i >= 0 {
+				rule.add = rule.add[:i] // Continuation flags are not supported.
+			}
+			cond := "."
+			if len(fields) >= 5 {
+				cond = fields[4]
+			}
+			rule.cond = parseCondition(cond)
+			a.rules = append(a.rules, rule)
+		}
+	}
+	return aff, nil
+}
+
+// zero turns the affix file's "0", meaning nothing, into the empty string.
+func zero(s string) string {
+	if s == "0" {
+		return ""
+	}
+	return s
+}
+
+// parseCondition parses a rule condition such as "[^aeiou]y".
+func parseCondition(s string) []charClass {
+	if s == "." {
+		return nil
+	}
+	var cond []charClass
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			cond = append(cond, charClass{any: true})
+			s = s[1:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				end = len(s)
+			}
+			c := charClass{runes: s[1:end]}
+			if strings.HasPrefix(c.runes, "^") {
+				c.negate = true
+				c.runes = c.runes[1:]
+			}
+			cond = append(cond, c)
+			s = s[min(end+1, len(s)):]
+		default:
+			_, wid := utf8.DecodeRuneInString(s)
+			cond = append(cond, charClass{runes: s[:wid]})
+			s = s[wid:]
+		}
+	}
+	return cond
+}
+
+// flags splits the flags of a dictionary entry according to the FLAG type.
+func (aff *affixFile) flags(s string) []string {
+	var flags []string
+	switch aff.flagType {
+	case "long":
+		for i := 0; i+1 < len(s); i += 2 {
+			flags = append(flags, s[i:i+2])
+		}
+	case "num":
+		flags = strings.Split(s, ",")
+	default:
+		for _, r := range s {
+			flags = append(flags, string(r))
+		}
+	}
+	return flags
+}
+
+// expand appends the stem and all its affixed forms to words.
+func (aff *affixFile) expand(words []string, stem string, flags []string) []string {
+	words = append(words, stem)
+	var suffixed []string
+	for _, f := range flags {
+		a := aff.affixes[f]
+		if a == nil || a.prefix {
+			continue
+		}
+		for _, r := range a.rules {
+			if w, ok := r.applySuffix(stem); ok {
+				words = append(words, w)
+				if a.cross {
+					suffixed = append(suffixed, w)
+				}
+			}
+		}
+	}
+	for _, f := range flags {
+		a := aff.affixes[f]
+		if a == nil || !a.prefix {
+			continue
+		}
+		for _, r := range a.rules {
+			if w, ok := r.applyPrefix(stem); ok {
+				words = append(words, w)
+			}
+			if !a.cross {
+				continue
+			}
+			for _, s := range suffixed {
+				if w, ok := r.applyPrefix(s); ok {
+					words = append(words, w)
+				}
+			}
+		}
+	}
+	return words
+}
+
+func (r affixRule) applySuffix(stem string) (string, bool) {
+	runes := []rune(stem)
+	if len(r.cond) > len(runes) || !strings.HasSuffix(stem, r.strip) {
+		return "", false
+	}
+	tail := runes[len(runes)-len(r.cond):]
+	for i, c := range r.cond {
+		if !c.match(tail[i]) {
+			return "", false
+		}
+	}
+	return strings.TrimSuffix(stem, r.strip) + r.add, true
+}
+
+func (r affixRule) applyPrefix(stem string) (string, bool) {
+	runes := []rune(stem)
+	if len(r.cond) > len(runes) || !strings.HasPrefix(stem, r.strip) {
+		return "", false
+	}
+	for i, c := range r.cond {
+		if !c.match(runes[i]) {
+			return "", false
+		}
+	}
+	return r.add + strings.TrimPrefix(stem, r.strip), true
+}
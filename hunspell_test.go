@@ -0,0 +1,168 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var hunspellTests = []struct {
+	name string
+	aff  string
+	dic  string
+	want []string
+}{
+	{
+		name: "suffix",
+		aff: `SFX S Y 3
+SFX S   y     ies        [^aeiou]y
+SFX S   0     s          [aeiou]y
+SFX S   0     s          [^y]
+`,
+		dic:  "3\ncity/S\nday/S\ncat/S\n",
+		want: []string{"cat", "cats", "cities", "city", "day", "days"},
+	},
+	{
+		name: "prefix and cross product",
+		aff: `PFX U Y 1
+PFX U   0     un         .
+
+SFX D Y 2
+SFX D   0     ed         [^e]
+SFX D   0     d          e
+`,
+		dic:  "2\nlock/UD\nbake/D\n",
+		want: []string{"bake", "baked", "lock", "locked", "unlock", "unlocked"},
+	},
+	{
+		name: "no cross product",
+		aff: `PFX U N 1
+PFX U   0     un         .
+
+SFX D Y 1
+SFX D   0     ed         .
+`,
+		dic:  "lock/UD\n",
+		want: []string{"lock", "locked", "unlock"},
+	},
+	{
+		name: "strip prefix",
+		aff: `PFX A Y 1
+PFX A   e     a          e
+`,
+		dic:  "enter/A\nopen/A\n",
+		want: []string{"anter", "enter", "open"},
+	},
+	{
+		name: "long flags",
+		aff: `FLAG long
+SFX Aa Y 1
+SFX Aa  0     s          .
+SFX Bb Y 1
+SFX Bb  0     er         .
+`,
+		dic:  "1\nwalk/AaBb\n",
+		want: []string{"walk", "walker", "walks"},
+	},
+	{
+		name: "numeric flags",
+		aff: `FLAG num
+SFX 101 Y 1
+SFX 101 0     s          .
+SFX 7 Y 1
+SFX 7   0     ing        .
+`,
+		dic:  "1\nsing/101,7\n",
+		want: []string{"sing", "singing", "sings"},
+	},
+	{
+		name: "comments, fields, and continuation flags",
+		aff: `# A comment.
+SFX S Y 1
+SFX S   0     s/X        .
+`,
+		dic:  "2\n# comment\ndog/S po:noun\ncat\tst:cat\n\n",
+		want: []string{"cat", "dog", "dogs"},
+	},
+	{
+		name: "no count line",
+		aff:  "",
+		dic:  "alpha\nbeta\n",
+		want: []string{"alpha", "beta"},
+	},
+	{
+		name: "latin1",
+		aff:  "SET ISO8859-1\nSFX S Y 1\nSFX S   0     s          .\n",
+		dic:  "1\ncaf\xe9/S\n",
+		want: []string{"café", "cafés"},
+	},
+}
+
+func TestReadHunspell(t *testing.T) {
+	for _, test := range hunspellTests {
+		dir := t.TempDir()
+		dic := filepath.Join(dir, "test.dic")
+		if err := os.WriteFile(filepath.Join(dir, "test.aff"), []byte(test.aff), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dic, []byte(test.dic), 0666); err != nil {
+			t.Fatal(err)
+		}
+		words, err := readHunspell(dic)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		slices.Sort(words)
+		if !slices.Equal(words, test.want) {
+			t.Errorf("%s: got %q; want %q", test.name, words, test.want)
+		}
+	}
+}
+
+func TestReadAffixFileErrors(t *testing.T) {
+	tests := []string{
+		"SFX S Y\n",
+		"SFX S Y 1\nSFX S 0\n",
+	}
+	for _, aff := range tests {
+		file := filepath.Join(t.TempDir(), "test.aff")
+		if err := os.WriteFile(file, []byte(aff), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readAffixFile(file); err == nil {
+			t.Errorf("%q: no error", aff)
+		}
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		cond  string
+		stem  string
+		match bool
+	}{
+		{".", "a", true},
+		{"y", "city", true},
+		{"y", "cat", false},
+		{"[^aeiou]y", "city", true},
+		{"[^aeiou]y", "day", false},
+		{"[aeiou]y", "day", true},
+		{".y", "y", false},
+		{"[ei]", "café", false},
+		{"[éè]", "café", true},
+		{"é", "café", true},
+		{"[ab", "xa", true},
+	}
+	for _, test := range tests {
+		r := affixRule{add: "s", cond: parseCondition(test.cond)}
+		if _, ok := r.applySuffix(test.stem); ok != test.match {
+			t.Errorf("condition %q on %q: got %t; want %t", test.cond, test.stem, ok, test.match)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ignored words are left out of the statistics and never reported as
+// unlikely, although they are still checked for repeats. Stop words, listed
+// in the files named by -stop, are ignored; unlike known words, which still
+// count in the statistics, they suit boilerplate that would distort them.
+
+var (
+	minLen = flag.Int("minlen", 0, "ignore words with fewer than this many characters")
+	maxLen = flag.Int("maxlen", 0, "ignore words with more than this many characters; 0 means no limit")
+
+	skipAcronyms = flag.Bool("skip-acronyms", false, "ignore words written entirely in capitals, such as HTTP")
+	skipDigits   = flag.Bool("skip-digits", false, "ignore words containing decimal digits")
+
+	stopFiles = flag.String("stop", "", "comma-separated `files` listing stop words, to be ignored")
+
+	skipURLs   = flag.Bool("skip-urls", false, "skip tokens that look like URLs, such as https://go.dev/doc")
+	skipEmails = flag.Bool("skip-emails", false, "skip tokens that look like email addresses")
+	skipPaths  = flag.Bool("skip-paths", false, "skip tokens that look like file paths, such as ./cmd/typo or C:\\temp")
+	skipHashes = flag.Bool("skip-hashes", false, "skip tokens that look like UUIDs or hexadecimal hashes, such as 4db591c")
+)
+
+// Tokens that are not words at all, such as URLs, are skipped, by the
+// -skip-urls flag and its kin, before they are split into words. Unlike
+// ignored words, they are not even checked for repeats.
+
+var (
+	urlToken   = regexp.MustCompile(`^(?i:[a-z][a-z0-9+.-]*://\S+|www\.\S+\.\S+|mailto:\S+)$`)
+	emailToken = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[\pL]{2,}$`)
+	// A path starts at the root, the home directory, a drive, or a
+	// relative directory, or has several elements, or names a file with a
+	// suffix, so that and/or is not taken for one.
+	pathToken = regexp.MustCompile(`^(?:(?:~|\.{1,2}|[A-Za-z]:)?[/\\][^/\\\s]*(?:[/\\][^/\\\s]*)*|[^/\\\s]+(?:[/\\][^/\\\s]+){2,}|[^/\\\s]+(?:[/\\][^/\\\s]+)*[/\\][^/\\\s]+\.[A-Za-z0-9]{1,5})$`)
+	hashToken = regexp.MustCompile(`^(?:[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]*[0-9][0-9a-fA-F]*)$`)
+)
+
+// skipped reports whether the token, trimmed of punctuation, is to be
+// skipped as something other than words.
+func skipped(token string) bool {
+	switch {
+	case *skipURLs && urlToken.MatchString(token):
+	case *skipEmails && emailToken.MatchString(token):
+	case *skipPaths && pathToken.MatchString(token):
+	case *skipHashes && len(token) >= 7 && hashToken.MatchString(token) && strings.IndexFunc(token, unicode.IsLetter) >= 0:
+	default:
+		return false
+	}
+	return true
+}
+
+// stopWords holds the folded stop words.
+var stopWords = make(map[string]bool)
+
+// loadStopWords reads the lists named by -stop.
+func loadStopWords() {
+	for _, file := range strings.Split(*stopFiles, ",") {
+		if file == "" {
+			continue
+		}
+		list, err := readDictionary(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			os.Exit(2)
+		}
+		logf("-stop: %d words from %s", len(list), file)
+		for _, w := range list {
+			stopWords[fold(w)] = true
+		}
+	}
+}
+
+// ignoreREs holds the patterns of -ignore-re.
+var ignoreREs regexpList
+
+func init() {
+	flag.Var(&ignoreREs, "ignore-re", "ignore words matching the regular `expression`; may be repeated")
+	addFilter(filter{name: "skip-acronyms", on: func() bool { return *skipAcronyms }})
+	addFilter(filter{name: "skip-digits", on: func() bool { return *skipDigits }})
+	addFilter(filter{name: "skip-urls", on: func() bool { return *skipURLs }})
+	addFilter(filter{name: "skip-emails", on: func() bool { return *skipEmails }})
+	addFilter(filter{name: "skip-paths", on: func() bool { return *skipPaths }})
+	addFilter(filter{name: "skip-hashes", on: func() bool { return *skipHashes }})
+	addFilter(filter{name: "ignore-re", on: func() bool { return len(ignoreREs) > 0 }})
+	addFilter(filter{name: "stop", on: func() bool { return *stopFiles != "" }})
+}
+
+// regexpList is a flag.Value holding a list of regular expressions.
+type regexpList []*regexp.Regexp
+
+func (l *regexpList) String() string {
+	var s []string
+	for _, re := range *l {
+		s = append(s, re.String())
+	}
+	return strings.Join(s, " ")
+}
+
+func (l *regexpList) Set(s string) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, re)
+	return nil
+}
+
+// ignored reports whether the word is to be ignored.
+func ignored(text string) bool {
+	n := utf8.RuneCountInString(text)
+	if n < *minLen || *maxLen > 0 && n > *maxLen {
+		return true
+	}
+	if *skipAcronyms && isAcronym(text) {
+		return true
+	}
+	if *skipDigits && strings.IndexFunc(text, unicode.IsDigit) >= 0 {
+		return true
+	}
+	for _, re := range ignoreREs {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return len(stopWords) > 0 && stopWords[fold(text)]
+}
+
+// isAcronym reports whether the word has at least two letters, all of them upper case.
+func isAcronym(text string) bool {
+	letters := 0
+	for _, c := range text {
+		if unicode.IsLetter(c) {
+			if !unicode.IsUpper(c) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters >= 2
+}
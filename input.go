@@ -0,0 +1,232 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+var encodingName = flag.String("encoding", "", "read the input in the character `encoding`, such as latin1, windows-1252, or shift_jis, rather than UTF-8")
+
+// inputEncoding is the encoding named by -encoding, if any.
+var inputEncoding encoding.Encoding
+
+// setEncoding looks up the encoding named by -encoding, by its IANA name
+// or, failing that, its name in the HTML standard.
+func setEncoding() {
+	if *encodingName == "" {
+		return
+	}
+	e, err := ianaindex.IANA.Encoding(*encodingName)
+	if e == nil || err != nil {
+		e, err = htmlindex.Get(*encodingName)
+	}
+	if e == nil || err != nil {
+		fmt.Fprintf(os.Stderr, "typo: unknown encoding %q\n", *encodingName)
+		os.Exit(2)
+	}
+	inputEncoding = e
+}
+
+// Magic numbers of compressed files.
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+)
+
+// decode returns a reader for the text held in r. Input compressed with
+// gzip, bzip2, or xz, as recognized by its first bytes rather than its
+// name, is decompressed. There is no xz package in the standard library,
+// so xz input is passed through the xz command. The text is then made
+// UTF-8; see toUTF8. With -stream, which must not wait for the first
+// bytes of its input, the input is taken to be uncompressed, and UTF-8
+// unless -encoding says otherwise.
+func decode(r io.Reader) (io.Reader, error) {
+	if *streamMode {
+		if inputEncoding != nil {
+			return transform.NewReader(r, inputEncoding.NewDecoder()), nil
+		}
+		return r, nil
+	}
+	r, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	return toUTF8(r), nil
+}
+
+// decompress returns a reader for the decompressed contents of r.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(xzMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return bzip2.NewReader(br), nil
+	case bytes.HasPrefix(magic, xzMagic):
+		cmd := exec.Command("xz", "-dc")
+		cmd.Stdin = br
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("xz: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return bytes.NewReader(out), nil
+	}
+	return br, nil
+}
+
+// toUTF8 returns a reader for the text of r as UTF-8. With -encoding, the
+// text is in that encoding. Otherwise, a byte order mark selects UTF-8,
+// which has the mark removed, or UTF-16 in either byte order. Without a
+// mark, text in which most of the first characters have a zero byte in the
+// same place is taken to be UTF-16, as written by some Windows programs,
+// and anything else to be UTF-8.
+func toUTF8(r io.Reader) io.Reader {
+	if inputEncoding != nil {
+		return transform.NewReader(r, inputEncoding.NewDecoder())
+	}
+	br := bufio.NewReader(r)
+	start, _ := br.Peek(512)
+	e := textEncoding(start)
+	if e == nil {
+		return br
+	}
+	return transform.NewReader(br, e.NewDecoder())
+}
+
+// textEncoding returns the encoding in which toUTF8 reads text that begins
+// with start, or nil if it reads the text as it is. Its encoder writes the
+// text back as it was, byte order mark and all.
+func textEncoding(start []byte) encoding.Encoding {
+	switch {
+	case inputEncoding != nil:
+		return inputEncoding
+	case bytes.HasPrefix(start, []byte{0xEF, 0xBB, 0xBF}):
+		return unicode.UTF8BOM
+	case bytes.HasPrefix(start, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(start, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	}
+	if order, ok := utf16Order(start); ok {
+		return unicode.UTF16(order, unicode.IgnoreBOM)
+	}
+	return nil
+}
+
+// compressed reports whether the data is compressed, as recognized by
+// decompress.
+func compressed(data []byte) bool {
+	for _, magic := range [][]byte{gzipMagic, bzip2Magic, xzMagic} {
+		if bytes.HasPrefix(data, magic) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBOM reports whether the text begins with a byte order mark.
+func hasBOM(b []byte) bool {
+	return bytes.HasPrefix(b, []byte{0xEF, 0xBB, 0xBF}) ||
+		bytes.HasPrefix(b, []byte{0xFF, 0xFE}) ||
+		bytes.HasPrefix(b, []byte{0xFE, 0xFF})
+}
+
+// utf16Order guesses whether the text, which has no byte order mark, is
+// UTF-16, and returns its byte order if so. Text in scripts
+// whose characters all have zero bytes is not recognized, but it is seldom
+// without a mark.
+func utf16Order(b []byte) (unicode.Endianness, bool) {
+	if len(b) < 4 {
+		return unicode.LittleEndian, false
+	}
+	var zeros [2]int
+	for i, c := range b[:len(b)&^1] {
+		if c == 0 {
+			zeros[i%2]++
+		}
+	}
+	half := len(b) / 2
+	switch {
+	case zeros[1] > half*3/4 && zeros[0] == 0:
+		return unicode.LittleEndian, true
+	case zeros[0] > half*3/4 && zeros[1] == 0:
+		return unicode.BigEndian, true
+	}
+	return unicode.LittleEndian, false
+}
+
+// Lines end with \n, \r\n, or, as in old Mac files, a lone \r, whatever
+// the platform, so no \r is left in a line.
+
+// scanLines is a bufio.SplitFunc that splits the input into lines.
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	i := bytes.IndexAny(data, "\r\n")
+	switch {
+	case i < 0:
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	case data[i] == '\n':
+		return i + 1, data[:i], nil
+	case i+1 < len(data):
+		if data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	case atEOF:
+		return i + 1, data[:i], nil
+	}
+	return 0, nil, nil // The \r may be followed by \n.
+}
+
+// lineEndings makes \n of all line endings.
+var lineEndings = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// splitLines splits the text into lines, as strings.Split(text, "\n") does
+// for text with only \n line endings.
+func splitLines(text string) []string {
+	if strings.IndexByte(text, '\r') >= 0 {
+		text = lineEndings.Replace(text)
+	}
+	return strings.Split(text, "\n")
+}
+
+// splitLinesAfter splits the data into lines, each with its line ending.
+func splitLinesAfter(data []byte) [][]byte {
+	var lines [][]byte
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "\r\n")
+		if i < 0 {
+			break
+		}
+		if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+			i++
+		}
+		lines = append(lines, data[:i+1])
+		data = data[i+1:]
+	}
+	return append(lines, data)
+}
@@ -0,0 +1,214 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The -jsonpath flag selects the strings of .json files to check by a path
+// in the usual notation: $ is the document, .name or ['name'] a member of
+// an object, [3] an element of an array, * any member or element, and
+// ..name a member at any depth below, as in $.items[*].description.
+
+var jsonPath = flag.String("jsonpath", "", "check only the strings of .json files at the `path`, such as '$.items[*].description'")
+
+func init() {
+	addFilter(filter{name: "jsonpath", on: func() bool { return *jsonPath != "" }, selects: jsonPathFilter})
+}
+
+// A pathStep is a step of a path: a member name, an array index, or any
+// member or element, optionally at any depth.
+type pathStep struct {
+	name    string
+	index   int // -1 if the step is a name or wildcard.
+	any     bool
+	descend bool // The step may be at any depth, as after "..".
+}
+
+// A pathElem is an element of the path of a value in a document: a member
+// name or, if index is not negative, an array index.
+type pathElem struct {
+	name  string
+	index int
+}
+
+// jsonSteps holds the steps of the parsed -jsonpath.
+var jsonSteps []pathStep
+
+// setJSONPath parses -jsonpath.
+func setJSONPath() {
+	if *jsonPath == "" {
+		return
+	}
+	steps, err := parseJSONPath(*jsonPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: -jsonpath %s: %s\n", *jsonPath, err)
+		os.Exit(2)
+	}
+	jsonSteps = steps
+}
+
+// parseJSONPath returns the steps of the path.
+func parseJSONPath(s string) ([]pathStep, error) {
+	s = strings.TrimPrefix(s, "$")
+	var steps []pathStep
+	for s != "" {
+		step := pathStep{index: -1}
+		switch {
+		case strings.HasPrefix(s, ".."):
+			step.descend = true
+			s = s[2:]
+		case s[0] == '.':
+			s = s[1:]
+		case s[0] == '[':
+		default:
+			return nil, fmt.Errorf("unexpected %q", s)
+		}
+		if s != "" && s[0] == '[' {
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing ]")
+			}
+			sel := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+			switch {
+			case sel == "*":
+				step.any = true
+			case len(sel) >= 2 && (sel[0] == '\'' || sel[0] == '"') && sel[len(sel)-1] == sel[0]:
+				step.name = sel[1 : len(sel)-1]
+			default:
+				n, err := strconv.Atoi(sel)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("bad selector [%s]", sel)
+				}
+				step.index = n
+			}
+		} else {
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("missing name")
+			}
+			step.name, s = s[:end], s[end:]
+			step.any = step.name == "*"
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// matches reports whether the step matches the element.
+func (s pathStep) matches(e pathElem) bool {
+	switch {
+	case s.any:
+		return true
+	case s.index >= 0:
+		return e.index == s.index
+	}
+	return e.index < 0 && e.name == s.name
+}
+
+// matchPath reports whether the steps match the whole path.
+func matchPath(steps []pathStep, path []pathElem) bool {
+	if len(steps) == 0 {
+		return len(path) == 0
+	}
+	s := steps[0]
+	if !s.descend {
+		return len(path) > 0 && s.matches(path[0]) && matchPath(steps[1:], path[1:])
+	}
+	for i := range path {
+		if s.matches(path[i]) && matchPath(steps[1:], path[i+1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// identifier matches the member names that may follow a dot in a path.
+var identifier = regexp.MustCompile(`^[\pL_][\pL\pN_]*$`)
+
+// pathString returns the path in the notation of -jsonpath.
+func pathString(path []pathElem) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, e := range path {
+		switch {
+		case e.index >= 0:
+			fmt.Fprintf(&b, "[%d]", e.index)
+		case identifier.MatchString(e.name):
+			b.WriteString("." + e.name)
+		default:
+			fmt.Fprintf(&b, "[%q]", e.name)
+		}
+	}
+	return b.String()
+}
+
+// jsonPathFilter returns a filter that blanks out all but the strings of
+// the .json file at the path selected by -jsonpath, or nil if the file is
+// not one. It records the path of each string as a line note.
+func jsonPathFilter(file string) lineFilter {
+	if strings.ToLower(filepath.Ext(file)) != ".json" {
+		return nil
+	}
+	notes := make(map[int][]textNote)
+	lineNotes[file] = notes
+	lineNum := 0
+	// The stack holds an element for each enclosing object or array: the
+	// member or element being read.
+	var stack []pathElem
+	return func(line string) string {
+		lineNum++
+		v := newValueLine(line)
+		for i := 0; i < len(line); {
+			switch c := line[i]; c {
+			case '"':
+				end := closingQuote(line, i)
+				n := len(stack)
+				if end > 0 && n > 0 && stack[n-1].index < 0 && strings.HasPrefix(strings.TrimLeft(line[end:], " \t"), ":") {
+					stack[n-1].name = line[i+1 : end-1]
+					v.blank(i, end)
+					i = end
+					continue
+				}
+				keep := matchPath(jsonSteps, stack)
+				if keep {
+					notes[lineNum] = append(notes[lineNum], textNote{i + 1, pathString(stack)})
+				}
+				i, _ = v.quoted(i, `"`, keep)
+				continue
+			case '{':
+				stack = append(stack, pathElem{index: -1})
+			case '[':
+				stack = append(stack, pathElem{index: 0})
+			case '}', ']':
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+			case ',':
+				if n := len(stack); n > 0 {
+					if stack[n-1].index >= 0 {
+						stack[n-1].index++
+					} else {
+						stack[n-1].name = ""
+					}
+				}
+			}
+			v.blank(i, i+1)
+			i++
+		}
+		return v.String()
+	}
+}
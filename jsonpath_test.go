@@ -0,0 +1,90 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+// member and element make path elements for the tests.
+func member(s string) pathElem { return pathElem{name: s, index: -1} }
+func element(i int) pathElem   { return pathElem{index: i} }
+
+func TestMatchPath(t *testing.T) {
+	items := []pathElem{member("items"), element(0), member("description")}
+	tests := []struct {
+		path  string
+		elems []pathElem
+		want  bool
+	}{
+		{"$", nil, true},
+		{"$", items, false},
+		{"$.items[*].description", items, true},
+		{"$.items[0].description", items, true},
+		{"$.items[1].description", items, false},
+		{"$.items.*.description", items, true},
+		{"$['items'][*]['description']", items, true},
+		{`$["items"][*]["description"]`, items, true},
+		{"$[ 'items' ][ 0 ].description", items, true},
+		{"$.items[*]", items, false},
+		{"$.items[*].description.x", items, false},
+		{"$..description", items, true},
+		{"$..items..description", items, true},
+		{"$..[0].description", items, true},
+		{"$..title", items, false},
+		{"$.*.*.*", items, true},
+		{"$.*.*", items, false},
+		{"$.items.0.description", items, false},
+		{"$['a.b']", []pathElem{member("a.b")}, true},
+		{"$.a.b", []pathElem{member("a.b")}, false},
+		{"$.a", []pathElem{element(0)}, false},
+		{"$[0]", []pathElem{member("0")}, false},
+		{"$..x", []pathElem{member("x"), member("y"), member("x")}, true},
+	}
+	for _, test := range tests {
+		steps, err := parseJSONPath(test.path)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		if got := matchPath(steps, test.elems); got != test.want {
+			t.Errorf("%s on %s: got %t; want %t", test.path, pathString(test.elems), got, test.want)
+		}
+	}
+}
+
+func TestParseJSONPathErrors(t *testing.T) {
+	for _, path := range []string{
+		"$x",
+		"items[*].description",
+		"$.items[0",
+		"$.items[-1]",
+		"$.items[x]",
+		"$.items['x]",
+		"$.",
+		"$..",
+		"$.a..",
+	} {
+		if steps, err := parseJSONPath(path); err == nil {
+			t.Errorf("%s: got %v; want error", path, steps)
+		}
+	}
+}
+
+func TestPathString(t *testing.T) {
+	tests := []struct {
+		elems []pathElem
+		want  string
+	}{
+		{nil, "$"},
+		{[]pathElem{member("items"), element(2), member("description")}, "$.items[2].description"},
+		{[]pathElem{member("a b"), member("_x1")}, `$["a b"]._x1`},
+		{[]pathElem{member("1x"), member("")}, `$["1x"][""]`},
+		{[]pathElem{member("café")}, "$.café"},
+	}
+	for _, test := range tests {
+		if got := pathString(test.elems); got != test.want {
+			t.Errorf("pathString(%v) = %s; want %s", test.elems, got, test.want)
+		}
+	}
+}
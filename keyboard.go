@@ -0,0 +1,118 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"robpike.io/cmd/typo/dict"
+)
+
+var keyboard = flag.String("keyboard", "qwerty", "rank suggestions by the adjacency of keys in the keyboard `layout`: qwerty, qwertz, azerty, dvorak, or a layout file")
+
+// adjacentKeys holds the pairs of keys that are next to each other on the
+// keyboard, in both orders. It is loaded when first needed.
+var adjacentKeys map[[2]rune]bool
+
+// adjacent reports whether the keys for a and b are next to each other.
+func adjacent(a, b rune) bool {
+	if adjacentKeys == nil {
+		adjacentKeys = make(map[[2]rune]bool)
+		data, err := findKeyboard(*keyboard)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			return false
+		}
+		parseKeyboard(data)
+	}
+	return adjacentKeys[[2]rune{unicode.ToLower(a), unicode.ToLower(b)}]
+}
+
+// findKeyboard returns the description of the layout, which is a file if
+// its name has a slash or ends in .txt, and otherwise the layout of that
+// name in the keyboard subdirectory of typo's data directories or the
+// built-in one.
+func findKeyboard(name string) ([]byte, error) {
+	if strings.ContainsRune(name, filepath.Separator) || strings.Contains(name, "/") || strings.HasSuffix(name, ".txt") {
+		return os.ReadFile(name)
+	}
+	if file, ok := findData(filepath.Join("keyboard", name+".txt")); ok {
+		return os.ReadFile(file)
+	}
+	if data, ok := dict.Keyboards[name]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("no keyboard layout %q", name)
+}
+
+// parseKeyboard records the adjacent keys of the layout. Each line is a row
+// of keys separated by spaces and indented as on the keyboard, so a key is
+// next to those two columns away on its line and those at most one column
+// away on the lines above and below. Lines beginning with # are comments.
+func parseKeyboard(data []byte) {
+	type key struct {
+		r   rune
+		col int
+	}
+	var rows [][]key
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		var row []key
+		col := 0
+		for _, r := range line {
+			if r != ' ' {
+				row = append(row, key{unicode.ToLower(r), col})
+			}
+			col++
+		}
+		rows = append(rows, row)
+	}
+	link := func(a, b key) {
+		adjacentKeys[[2]rune{a.r, b.r}] = true
+		adjacentKeys[[2]rune{b.r, a.r}] = true
+	}
+	for i, row := range rows {
+		for j, k := range row {
+			if j > 0 && k.col-row[j-1].col <= 2 {
+				link(row[j-1], k)
+			}
+			if i == 0 {
+				continue
+			}
+			for _, above := range rows[i-1] {
+				if d := above.col - k.col; -1 <= d && d <= 1 {
+					link(above, k)
+				}
+			}
+		}
+	}
+}
+
+// slips returns the number of runes of b that differ from those of a, a word
+// of the same length, by the press of an adjacent key, or zero if the words'
+// lengths differ.
+func slips(a, b []rune) int {
+	if len(a) != len(b) {
+		return 0
+	}
+	n := 0
+	for i := range a {
+		if a[i] != b[i] && adjacent(a[i], b[i]) {
+			n++
+		}
+	}
+	return n
+}
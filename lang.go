@@ -0,0 +1,182 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"robpike.io/cmd/typo/dict"
+)
+
+// The -lang flag selects the word lists of known words. For each language,
+// such as en_GB, typo looks for a list named en_GB.txt, or a hunspell
+// dictionary en_GB.dic with its affix file en_GB.aff, in the dict
+// subdirectory of each of typo's data directories (see datadir.go) and
+// then in the system's hunspell directories. Failing those it
+// tries en the same way, and then the lists built in. A list holds words
+// separated by white space. The -dict flag names more lists or dictionaries
+// directly. The user's own list, words.local, is always loaded; see learn.go.
+
+var (
+	contractions = flag.Bool("contractions", false, "accept contractions and possessives of known words, such as don't and typo's")
+
+	langs = flag.String("lang", "en", "comma-separated `languages` of the known-word lists, such as en_GB,de")
+	dicts = flag.String("dict", "", "comma-separated word lists or hunspell .dic `files` of more known words")
+)
+
+// hunspellDirs lists the directories in which systems install hunspell dictionaries.
+var hunspellDirs = []string{
+	"/usr/share/hunspell",
+	"/usr/share/myspell",
+	"/usr/share/myspell/dicts",
+	"/usr/local/share/hunspell",
+	"/Library/Spelling",
+}
+
+// loadDictionaries adds the words of the lists for the languages, and of
+// those named by -dict, to the known words.
+func loadDictionaries() {
+	for _, lang := range strings.Split(*langs, ",") {
+		lang = strings.TrimSpace(lang)
+		if lang == "" {
+			continue
+		}
+		list, source, ok := findDictionary(lang)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "typo: no word list for language %q\n", lang)
+			continue
+		}
+		logf("language %s: %d words from %s", lang, len(list), source)
+		for _, w := range list {
+			known[fold(w)] = true
+		}
+	}
+	for _, file := range strings.Split(*dicts, ",") {
+		if file == "" {
+			continue
+		}
+		list, err := readDictionary(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			continue
+		}
+		logf("-dict: %d words from %s", len(list), file)
+		for _, w := range list {
+			known[fold(w)] = true
+		}
+	}
+	loadLocalWords()
+	logf("%d known words in all", len(known))
+	if len(known) == 0 {
+		fmt.Fprintf(os.Stderr, "typo: warning: no known words; every unlikely word will be reported\n")
+	}
+}
+
+// findDictionary returns the word list for the language and where it was found.
+func findDictionary(lang string) ([]string, string, bool) {
+	names := []string{lang}
+	if i := strings.IndexAny(lang, "_-"); i > 0 {
+		names = append(names, lang[:i]) // en_GB falls back to en.
+	}
+	var dirs []string
+	for _, dir := range dataDirs() {
+		dirs = append(dirs, filepath.Join(dir, "dict"))
+	}
+	ours := len(dirs)
+	dirs = append(dirs, hunspellDirs...)
+	for _, name := range names {
+		for i, dir := range dirs {
+			for _, suffix := range []string{".txt", ".dic"} {
+				if suffix == ".txt" && i >= ours {
+					continue // Plain lists live only in our own directory.
+				}
+				file := filepath.Join(dir, name+suffix)
+				list, err := readDictionary(file)
+				if err == nil {
+					return list, file, true
+				}
+			}
+		}
+		if list, ok := dict.Bundled[name]; ok {
+			return dict.Words(list), "the built-in list " + name, true
+		}
+	}
+	return nil, "", false
+}
+
+// readDictionary returns the words in the file, which is a hunspell
+// dictionary if its name ends in .dic and a plain list otherwise.
+func readDictionary(file string) ([]string, error) {
+	if strings.HasSuffix(file, ".dic") {
+		return readHunspell(file)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return dict.Words(data), nil
+}
+
+// apostrophes maps typographic apostrophes to the ASCII one.
+var apostrophes = strings.NewReplacer("\u2019", "'", "\u2018", "'", "\u02BC", "'")
+
+// form returns the word in Unicode normalization form C, so that letters
+// with diacritics compare equal however they were encoded, and with
+// typographic apostrophes made ASCII, so "don’t" and "don't" are the same.
+// ASCII text is already in that form, and is returned as is.
+func form(s string) string {
+	if isASCII(s) {
+		return s
+	}
+	if strings.ContainsAny(s, "\u2019\u2018\u02BC") {
+		s = apostrophes.Replace(s)
+	}
+	return norm.NFC.String(s)
+}
+
+// fold returns the form of the word used to look it up in the known words:
+// normalized and in lower case. Lists for languages that capitalize nouns
+// thus match those nouns wherever they appear.
+func fold(s string) string {
+	return strings.ToLower(form(s))
+}
+
+// isASCII reports whether s is entirely ASCII.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// isKnown reports whether the folded word is known. With -contractions,
+// a word is also known if it is a contraction or possessive of a known
+// word: if it is known without its apostrophes or without what follows
+// the apostrophe, as in "typo's", or without "n't", as in "doesn't".
+func isKnown(lower string) bool {
+	if known[lower] {
+		return true
+	}
+	if !*contractions || !strings.Contains(lower, "'") {
+		return false
+	}
+	if known[strings.ReplaceAll(lower, "'", "")] {
+		return true
+	}
+	if i := strings.Index(lower, "n't"); i > 0 && known[lower[:i]] {
+		return true
+	}
+	i := strings.Index(lower, "'")
+	return i > 0 && known[lower[:i]]
+}
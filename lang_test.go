@@ -0,0 +1,57 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"robpike.io/cmd/typo/dict"
+	"robpike.io/cmd/typo/trigram"
+)
+
+// TestEnglishUnchanged checks that normalization leaves English as it was:
+// over the built-in English list, the statistics gathered from the
+// normalized words give every word the score it had without normalization,
+// and folding is plain lower-casing.
+func TestEnglishUnchanged(t *testing.T) {
+	list := dict.Words(dict.Bundled["en"])
+	before, after := trigram.New(), trigram.New()
+	for _, w := range list {
+		before.Add(w)
+		after.Add(form(w))
+	}
+	for _, w := range list {
+		if b, a := before.Score(w), after.Score(form(w)); b != a {
+			t.Errorf("%q: score %g before normalization, %g after", w, b, a)
+		}
+		if got, want := fold(w), strings.ToLower(w); got != want {
+			t.Errorf("fold(%q) = %q; want %q", w, got, want)
+		}
+		if got, want := lowerCase(w), strings.ToLower(w); got != want {
+			t.Errorf("lowerCase(%q) = %q; want %q", w, got, want)
+		}
+	}
+}
+
+func TestForm(t *testing.T) {
+	tests := []struct {
+		in, form, fold string
+	}{
+		{"typo", "typo", "typo"},
+		{"Typo", "Typo", "typo"},
+		{"don\u2019t", "don't", "don't"},
+		{"cafe\u0301", "caf\u00e9", "caf\u00e9"}, // Combining acute accent.
+		{"CAFE\u0301", "CAF\u00c9", "caf\u00e9"},
+	}
+	for _, test := range tests {
+		if got := form(test.in); got != test.form {
+			t.Errorf("form(%q) = %q; want %q", test.in, got, test.form)
+		}
+		if got := fold(test.in); got != test.fold {
+			t.Errorf("fold(%q) = %q; want %q", test.in, got, test.fold)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"robpike.io/cmd/typo/dict"
+)
+
+// The user's own list of known words is words.local in the typo subdirectory
+// of the user's data directory, $XDG_DATA_HOME/typo/words.local on Unix. It
+// is loaded with the other lists. The -learn flag adds to it: with -fix,
+// the answer a adds the word under review; otherwise the words listed in
+// the files named as arguments, or on standard input, are added.
+
+var learnMode = flag.Bool("learn", false, "add words to the personal list of known words: by answering a with -fix, or from the input")
+
+// localWordsFile returns the name of the user's list of known words, or
+// the empty string if there is no place for it.
+func localWordsFile() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "typo", "words.local")
+}
+
+// loadLocalWords adds the user's list of known words, if any, to the known words.
+func loadLocalWords() {
+	file := localWordsFile()
+	if file == "" {
+		return
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	list := dict.Words(data)
+	logf("local words: %d words from %s", len(list), file)
+	for _, w := range list {
+		known[fold(w)] = true
+	}
+}
+
+// learn appends the words to the user's list of known words and adds them
+// to the known words.
+func learn(words []string) error {
+	file := localWordsFile()
+	if file == "" {
+		return fmt.Errorf("no home directory for %s", "words.local")
+	}
+	var b strings.Builder
+	for _, w := range words {
+		if !known[fold(w)] {
+			known[fold(w)] = true
+			fmt.Fprintln(&b, w)
+		}
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	phonetic = nil // Rebuild it with the new words.
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// learnLists adds the words listed in the files, or on standard input if
+// there are none, to the user's list of known words.
+func learnLists(files []string) {
+	var list []string
+	if len(files) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			os.Exit(2)
+		}
+		list = dict.Words(data)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			os.Exit(2)
+		}
+		list = append(list, dict.Words(data)...)
+	}
+	if err := learn(list); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: learning: %s\n", err)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"container/heap"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// With -low-mem, typo does not keep a record of every word. Instead it reads
+// the files twice: once to count the digrams and trigrams, and again to
+// score each word against the counts, keeping only the -n most unlikely
+// distinct words, at their first locations, and the repeats. The words kept
+// are not interned, and those dropped give back their storage, so memory use
+// is then bounded by the size of the tables, not of the input, at the cost of
+// reading the input twice, so standard input cannot be used. So that the
+// -n words are those a normal run would report, the words in the -baseline
+// file, the limit of -n-per-file, and the OCR errors below the threshold
+// that -ocr adds are all taken into account as the words are kept.
+
+var lowMem = flag.Bool("low-mem", false, "read the files twice, keeping only the counts and the most unlikely words in memory")
+
+// lowMemWord, if not nil, is given each word in place of the word list.
+var lowMemWord func(text, line, file string, lineNum, byteNum int)
+
+// checkLowMem reports an error if -low-mem is combined with something it cannot do.
+func checkLowMem(files []string) {
+	if !*lowMem {
+		return
+	}
+	bad := ""
+	switch {
+	case len(files) == 0:
+		bad = "-low-mem needs files named as arguments"
+	case *perFile, *summary, *topPercent > 0, *allLocs, *staged, *lspMode, *serveAddr != "", *confusables, *consistency, *freqMode, *showTotals, *showCounts, *format == "sqlite":
+		bad = "-low-mem does not work with -per-file, -summary, -top-percent, -all-locations, -staged, -lsp, -serve, -confusables, -consistency, -freq, -stats, -counts, or -format=sqlite"
+	}
+	if bad != "" {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", bad)
+		os.Exit(2)
+	}
+}
+
+// scanLowMem reads the files in two passes, as described above. It leaves
+// the unlikely words, scored, in the word list and returns the repeats.
+func scanLowMem(files []string) []*Word {
+	if corpus == nil {
+		lowMemWord = func(text, line, file string, lineNum, byteNum int) {
+			if !ignored(text) {
+				table.Add(form(text))
+			}
+		}
+		for _, f := range files {
+			add(f, nil)
+		}
+	}
+	var reps []*Word
+	var unlikely wordHeap
+	inHeap := make(map[string]bool)
+	inFile := make(map[string]int)  // Words in the heap from each file.
+	capped := make(map[string]bool) // Words pushed out by others of their file.
+	drop := func(w *Word) {
+		delete(inHeap, w.text())
+		inFile[w.file]--
+		w.release()
+	}
+	var r recent
+	lowMemWord = func(text, line, file string, lineNum, byteNum int) {
+		lower := lowerCase(text)
+		kept := func() *Word {
+			return newOwnWord(text, file, lineNum, byteNum, column(line, byteNum))
+		}
+		if r.repeatsAt(lower, wordLoc{file, lineNum, byteNum}) && !*noRepeats {
+			if w := kept(); inDiff(w) && !suppressed(w) {
+				reps = append(reps, w)
+			} else {
+				w.release()
+			}
+		}
+		if inHeap[text] || capped[text] || ignored(text) || isKnown(lower) || inBaseline("typo", file, text) {
+			return
+		}
+		score := table.Score(form(text))
+		if score < *threshold && !(*ocrMode && ocrReading(text) != "") {
+			return
+		}
+		w := kept()
+		w.score = score
+		if len(unlikely) >= *nTypos && (len(unlikely) == 0 || below(w, unlikely[0])) || !inDiff(w) || suppressed(w) {
+			w.release()
+			return
+		}
+		if *nPerFile > 0 && inFile[file] >= *nPerFile {
+			// The word displaces the least of its file's, if it ranks above it.
+			least := -1
+			for i, u := range unlikely {
+				if u.file == file && (least < 0 || below(u, unlikely[least])) {
+					least = i
+				}
+			}
+			if below(w, unlikely[least]) {
+				capped[w.text()] = true
+				w.release()
+				return
+			}
+			u := heap.Remove(&unlikely, least).(*Word)
+			capped[u.text()] = true
+			drop(u)
+		}
+		heap.Push(&unlikely, w)
+		inHeap[w.text()] = true
+		inFile[file]++
+		if len(unlikely) > *nTypos {
+			drop(heap.Pop(&unlikely).(*Word))
+		}
+	}
+	for _, f := range files {
+		add(f, nil)
+	}
+	lowMemWord = nil
+	words = unlikely
+	return reps
+}
+
+// wordHeap is a min-heap of words in the order of below.
+type wordHeap []*Word
+
+func (h wordHeap) Len() int      { return len(h) }
+func (h wordHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h wordHeap) Less(i, j int) bool {
+	return below(h[i], h[j])
+}
+
+// below reports whether the word v ranks below w in the report. The words
+// at or above the threshold rank above those below it, which are kept only
+// as OCR errors, as in typos; then the order is that of ByScore.
+func below(v, w *Word) bool {
+	if vAbove, wAbove := v.score >= *threshold, w.score >= *threshold; vAbove != wAbove {
+		return wAbove
+	}
+	if v.score != w.score {
+		return v.score < w.score
+	}
+	return before(w, v)
+}
+
+func (h *wordHeap) Push(x interface{}) {
+	*h = append(*h, x.(*Word))
+}
+
+func (h *wordHeap) Pop() interface{} {
+	old := *h
+	w := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return w
+}
@@ -0,0 +1,308 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A minimal Language Server Protocol server. It speaks JSON-RPC over a
+// pair of streams and publishes diagnostics for a document each time it
+// is opened or changed. Each document is analyzed on its own, so the
+// statistics come from that document alone.
+
+// lspLanguages are the language identifiers of the documents we check.
+var lspLanguages = map[string]bool{
+	"plaintext": true,
+	"markdown":  true,
+	"text":      true,
+}
+
+// lspMessage is a request or notification.
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// lspResult is a successful response.
+type lspResult struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+}
+
+// lspFailure is an error response.
+type lspFailure struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   *lspError        `json:"error"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code,omitempty"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspDocument struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Text       string `json:"text"`
+}
+
+// lspSeverity returns the diagnostic severity for the severity of a finding.
+func lspSeverity(severity string) int {
+	switch severity {
+	case "error":
+		return lspSevError
+	case "warning":
+		return lspWarning
+	}
+	return lspInformation
+}
+
+// Diagnostic severities.
+const (
+	lspSevError    = 1
+	lspWarning     = 2
+	lspInformation = 3
+)
+
+type lspServer struct {
+	r    *bufio.Reader
+	w    io.Writer
+	docs map[string]bool // URIs of the documents we are checking.
+}
+
+// serveLSP runs the language server until the client says to exit.
+func serveLSP(r io.Reader, w io.Writer) error {
+	s := &lspServer{
+		r:    bufio.NewReader(r),
+		w:    w,
+		docs: make(map[string]bool),
+	}
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// read returns the next message from the client.
+func (s *lspServer) read() (*lspMessage, error) {
+	header, err := textproto.NewReader(s.r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("bad Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(s.r, body); err != nil {
+		return nil, err
+	}
+	msg := new(lspMessage)
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// write sends a message to the client.
+func (s *lspServer) write(msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// reply answers the request with the given result or error.
+func (s *lspServer) reply(req *lspMessage, result interface{}, err *lspError) error {
+	if req.ID == nil {
+		// A notification; no reply wanted.
+		return nil
+	}
+	if err != nil {
+		return s.write(&lspFailure{JSONRPC: "2.0", ID: req.ID, Error: err})
+	}
+	return s.write(&lspResult{JSONRPC: "2.0", ID: req.ID, Result: result})
+}
+
+func (s *lspServer) handle(msg *lspMessage) error {
+	switch msg.Method {
+	case "initialize":
+		return s.reply(msg, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": 1, // Full.
+			},
+			"serverInfo": map[string]string{"name": "typo"},
+		}, nil)
+	case "shutdown":
+		return s.reply(msg, nil, nil)
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument lspDocument `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return err
+		}
+		doc := params.TextDocument
+		if !lspLanguages[doc.LanguageID] {
+			return nil
+		}
+		s.docs[doc.URI] = true
+		return s.check(doc.URI, doc.Text)
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument   lspDocument `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return err
+		}
+		uri := params.TextDocument.URI
+		if !s.docs[uri] || len(params.ContentChanges) == 0 {
+			return nil
+		}
+		// We asked for full synchronization, so the last change holds the whole text.
+		return s.check(uri, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument lspDocument `json:"textDocument"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return err
+		}
+		uri := params.TextDocument.URI
+		if !s.docs[uri] {
+			return nil
+		}
+		delete(s.docs, uri)
+		return s.publish(uri, []lspDiagnostic{})
+	}
+	if strings.HasPrefix(msg.Method, "$/") || msg.ID == nil {
+		// Notifications we don't understand may be ignored.
+		return nil
+	}
+	return s.reply(msg, nil, &lspError{Code: -32601, Message: "method not found: " + msg.Method})
+}
+
+// check analyzes the text of the document and publishes the results.
+func (s *lspServer) check(uri, text string) error {
+	reset()
+	add(uri, strings.NewReader(text))
+	lines := splitLines(text)
+	diags := []lspDiagnostic{}
+	if !*noRepeats {
+		for _, w := range repeats() {
+			diags = append(diags, lspDiagnostic{
+				Range:    lspWordRange(lines, w),
+				Severity: lspSeverity(severity(w, "repeat")),
+				Code:     "repeat",
+				Source:   "typo",
+				Message:  fmt.Sprintf("repeated word %q", w.text()),
+			})
+		}
+	}
+	stats()
+	for _, w := range words {
+		if w.score < *threshold || !w.candidate() {
+			continue
+		}
+		diags = append(diags, lspDiagnostic{
+			Range:    lspWordRange(lines, w),
+			Severity: lspSeverity(severity(w, "typo")),
+			Code:     "typo",
+			Source:   "typo",
+			Message:  fmt.Sprintf("possible typo %q (score %d)", w.text(), int(w.score)),
+		})
+	}
+	return s.publish(uri, diags)
+}
+
+func (s *lspServer) publish(uri string, diags []lspDiagnostic) error {
+	params, err := json.Marshal(map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diags,
+	})
+	if err != nil {
+		return err
+	}
+	return s.write(&lspMessage{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: params})
+}
+
+// lspWordRange returns the range of the word in the document, measured in UTF-16 code units as LSP requires.
+func lspWordRange(lines []string, w *Word) lspRange {
+	line := w.lineNum - 1
+	start := w.byteNum - 1
+	text := ""
+	if line < len(lines) {
+		text = lines[line]
+	}
+	if start > len(text) {
+		start = len(text)
+	}
+	end := start + len(w.text())
+	if end > len(text) {
+		end = len(text)
+	}
+	return lspRange{
+		Start: lspPosition{line, utf16Len(text[:start])},
+		End:   lspPosition{line, utf16Len(text[:end])},
+	}
+}
+
+// utf16Len returns the number of UTF-16 code units needed to encode s.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n++
+		if r >= 0x10000 && r <= utf8.MaxRune {
+			n++ // Surrogate pair.
+		}
+	}
+	return n
+}
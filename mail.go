@@ -0,0 +1,90 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"regexp"
+	"strings"
+)
+
+var mailMode = flag.Bool("mail", false, "check only the new text of mail messages, skipping headers, quotations, and signatures")
+
+func init() {
+	addFilter(filter{name: "mail", on: func() bool { return *mailMode }, markup: mailFilter})
+}
+
+var (
+	mailBoundary = regexp.MustCompile(`(?i)boundary="?([^";\s]+)"?`)
+	mailHeader   = regexp.MustCompile(`^[!-9;-~]+:`)
+	// On Mon, 1 Jan 2024, Ann <ann@example.com> wrote:
+	mailAttribution = regexp.MustCompile(`^On .* wrote:\s*$`)
+)
+
+// mailFilter returns a filter that blanks out all but the new text of mail
+// messages, either a single RFC 822 message or an mbox of them. What goes
+// are the headers of each message and of the parts of a MIME message;
+// parts that are not plain text; quoted lines, which start with '>', and
+// attribution lines such as "On Monday, Ann wrote:"; and signatures, which
+// follow a line holding "-- ".
+func mailFilter() lineFilter {
+	header := true // In the headers of a message or part.
+	skip := false  // In a signature or a part that is not text.
+	var boundaries []string
+	var headers []string // Lines of the current header.
+	prev := ""           // The previous line.
+	first := true
+	return func(line string) string {
+		blanked := blank(line, 0, len(line))
+		startsMessage := strings.HasPrefix(line, "From ") && (first || prev == "")
+		prev, first = line, false
+		switch {
+		case startsMessage:
+			// The start of a message in an mbox.
+			header, skip, boundaries, headers = true, false, nil, nil
+			return blanked
+		case isBoundary(line, boundaries):
+			header, skip, headers = true, false, nil
+			return blanked
+		case header:
+			if line != "" {
+				if !mailHeader.MatchString(line) && len(headers) > 0 && (line[0] == ' ' || line[0] == '\t') {
+					headers[len(headers)-1] += line // A continuation.
+				} else {
+					headers = append(headers, line)
+				}
+				return blanked
+			}
+			header = false
+			for _, h := range headers {
+				lower := strings.ToLower(h)
+				if m := mailBoundary.FindStringSubmatch(h); m != nil && strings.HasPrefix(lower, "content-type:") {
+					boundaries = append(boundaries, m[1])
+				}
+				if strings.HasPrefix(lower, "content-type:") && !strings.Contains(lower, "text/plain") ||
+					strings.HasPrefix(lower, "content-transfer-encoding:") && strings.Contains(lower, "base64") {
+					skip = true
+				}
+			}
+			return blanked
+		case skip, strings.HasPrefix(line, ">"), mailAttribution.MatchString(line):
+			return blanked
+		case line == "-- ":
+			skip = true
+			return blanked
+		}
+		return line
+	}
+}
+
+// isBoundary reports whether the line separates the parts of a MIME message.
+func isBoundary(line string, boundaries []string) bool {
+	for _, b := range boundaries {
+		if strings.HasPrefix(line, "--"+b) {
+			return true
+		}
+	}
+	return false
+}
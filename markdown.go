@@ -0,0 +1,53 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"regexp"
+	"strings"
+)
+
+var markdownMode = flag.Bool("markdown", false, "skip Markdown markup: code blocks and spans, link targets, and tags")
+
+func init() {
+	addFilter(filter{name: "markdown", on: func() bool { return *markdownMode }, markup: markdownFilter})
+}
+
+var (
+	// ``` or ~~~, with an optional info string.
+	mdFence = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+
+	mdInline = []*regexp.Regexp{
+		regexp.MustCompile("`[^`]+`"),                 // `code`
+		regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`), // [text](url) and ![alt](image)
+		regexp.MustCompile(`\[([^\]]*)\]\[[^\]]*\]`),  // [text][ref]
+		regexp.MustCompile(`^ {0,3}\[[^\]]+\]:.*`),    // [ref]: url
+		regexp.MustCompile(`<[a-zA-Z/!][^>]*>`),       // <tag> and <https://autolink>
+	}
+)
+
+// markdownFilter returns a filter that blanks out Markdown markup: fenced
+// code blocks, code spans, link and image targets, reference definitions,
+// and HTML tags. The text of links is kept.
+func markdownFilter() lineFilter {
+	fence := "" // The fence that ends the code block being skipped.
+	return func(line string) string {
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+			}
+			return blank(line, 0, len(line))
+		}
+		if m := mdFence.FindStringSubmatch(line); m != nil {
+			fence = m[1]
+			return blank(line, 0, len(line))
+		}
+		for _, re := range mdInline {
+			line = blankMatches(line, re)
+		}
+		return line
+	}
+}
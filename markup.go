@@ -0,0 +1,132 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Markup filters blank out the parts of a document that are not prose,
+// replacing them with spaces so the words that remain keep their lines
+// and columns.
+
+// A lineFilter blanks out the markup in each line of a document, given in
+// order. It may keep state from line to line.
+type lineFilter func(line string) string
+
+// A filter is a way, selected by a flag, of picking out or skipping parts
+// of the input. Each registers itself with addFilter from an init function
+// of the file that implements it, so markupFilter applies it and -v lists
+// it. A filter that is neither a selecting nor a markup line filter works
+// elsewhere, such as on whole files or on words, and is only listed.
+type filter struct {
+	name    string                       // The flag that selects the filter.
+	on      func() bool                  // Whether the flag selects the filter.
+	selects func(file string) lineFilter // Picks out the prose of the file, or returns nil if it does not know the file's kind.
+	markup  func() lineFilter            // Blanks out markup.
+}
+
+// filters holds the filters, in the order they were added.
+var filters []filter
+
+// addFilter adds the filter to those applied to the input.
+func addFilter(f filter) {
+	filters = append(filters, f)
+}
+
+// markupFilter returns a filter for the line filters selected by the flags,
+// preceded by one for the markers that suppress findings; see suppress.go.
+// The filters that pick out the comments, values, fields, columns, resource
+// and catalog strings, or captions of the file, which depend on its name,
+// come before those that blank out markup.
+func markupFilter(file string) lineFilter {
+	lfs := []lineFilter{suppressFilter(file)}
+	for _, f := range filters {
+		if f.selects != nil && f.on() {
+			if lf := f.selects(file); lf != nil {
+				lfs = append(lfs, lf)
+			}
+		}
+	}
+	for _, f := range filters {
+		if f.markup != nil && f.on() {
+			lfs = append(lfs, f.markup())
+		}
+	}
+	if len(lfs) == 1 {
+		return lfs[0]
+	}
+	return func(line string) string {
+		for _, f := range lfs {
+			line = f(line)
+		}
+		return line
+	}
+}
+
+// lineNotes holds, by file and line, notes on where the text of the line
+// comes from, such as the source references of a .po entry, set by the
+// filters that know. They are printed with the words they cover.
+var lineNotes = make(map[string]map[int][]textNote)
+
+// A textNote is a note on the text of a line from byteNum, counted from 1,
+// to the next note.
+type textNote struct {
+	byteNum int
+	text    string
+}
+
+// lineNote returns the suffix with which to print the word: the note on
+// its text, if any.
+func lineNote(w *Word) string {
+	if text := lineNoteText(w); text != "" {
+		return " (" + text + ")"
+	}
+	return ""
+}
+
+// lineNoteText returns the note on the text of the word, if any.
+func lineNoteText(w *Word) string {
+	text := ""
+	for _, n := range lineNotes[w.file][w.lineNum] {
+		if n.byteNum <= w.byteNum {
+			text = n.text
+		}
+	}
+	return text
+}
+
+// blank returns s with the bytes s[i:j] replaced by spaces.
+func blank(s string, i, j int) string {
+	return s[:i] + strings.Repeat(" ", j-i) + s[j:]
+}
+
+// blankMatches returns s with each match of the expression blanked out,
+// except for the text of any parenthesized subexpressions, which is kept.
+func blankMatches(s string, re *regexp.Regexp) string {
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		i := m[0]
+		for k := 2; k < len(m); k += 2 {
+			if m[k] < 0 {
+				continue
+			}
+			s = blank(s, i, m[k])
+			i = m[k+1]
+		}
+		s = blank(s, i, m[1])
+	}
+	return s
+}
+
+// indent returns the width of the leading white space of the line, or -1
+// if the line is blank.
+func indent(line string) int {
+	t := strings.TrimLeft(line, " \t")
+	if t == "" {
+		return -1
+	}
+	return len(line) - len(t)
+}
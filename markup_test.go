@@ -0,0 +1,33 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+// TestFilters checks that each filter is named for the flag that selects
+// it, and that none is selected by default.
+func TestFilters(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, f := range filters {
+		if flag.Lookup(f.name) == nil {
+			t.Errorf("filter %q is not named for a flag", f.name)
+		}
+		if seen[f.name] {
+			t.Errorf("filter %q added twice", f.name)
+		}
+		seen[f.name] = true
+		if f.on() {
+			t.Errorf("filter %q is on by default", f.name)
+		}
+	}
+	for _, name := range []string{"html", "po", "comments", "pdf", "filter-cmd", "skip-urls", "ignore-re", "stop"} {
+		if !seen[name] {
+			t.Errorf("no filter %q", name)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"unsafe"
+)
+
+// Files of at least mapMin bytes are memory-mapped rather than read, so the
+// lines, and the words taken from them, are views of the mapping rather
+// than copies. The words refer to the mapping until typo exits, so it is
+// not unmapped before then, and a file must not change while it is mapped.
+// Thus in the modes that write to files the user chose, -fix, -tui, and
+// -learn, nothing is mapped, nor is any file named by -o, -write-baseline,
+// or -dump-stats. Compressed files and text not in UTF-8 are read as usual
+// too, as is any file that cannot be mapped.
+const mapMin = 256 << 20
+
+// fromMapping reports whether the words being added are in a mapped file,
+// and so need not be copied when interned.
+var fromMapping bool
+
+// mapInput returns the contents of the file, memory-mapped, if it should be.
+func mapInput(f *os.File) ([]byte, bool) {
+	if *fixMode || *tuiMode || *learnMode || *streamMode || inputEncoding != nil {
+		return nil, false
+	}
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() || info.Size() < mapMin || int64(int(info.Size())) != info.Size() {
+		return nil, false
+	}
+	for _, out := range []string{*outFile, *writeBaselineFile, *dumpStatsFile} {
+		if out == "" {
+			continue
+		}
+		if o, err := os.Stat(out); err == nil && os.SameFile(info, o) {
+			return nil, false
+		}
+	}
+	data, err := mmap(f, int(info.Size()))
+	if err != nil {
+		return nil, false
+	}
+	if !plainText(data) {
+		munmap(data)
+		return nil, false
+	}
+	return data, true
+}
+
+// plainText reports whether the data is text that decode would pass
+// through unchanged: neither compressed nor in UTF-16 nor beginning with a
+// byte order mark.
+func plainText(data []byte) bool {
+	if compressed(data) {
+		return false
+	}
+	start := data[:min(len(data), 512)]
+	if hasBOM(start) {
+		return false
+	}
+	_, utf16 := utf16Order(start)
+	return !utf16
+}
+
+// readMapped calls fn for each line of the mapped data in turn.
+func readMapped(data []byte, fn func(lineNum int, line string)) {
+	fromMapping = true
+	defer func() { fromMapping = false }()
+	for lineNum := 1; len(data) > 0; lineNum++ {
+		advance, line, _ := scanLines(data, true)
+		totals.lines++
+		fn(lineNum, unsafe.String(unsafe.SliceData(line), len(line)))
+		data = data[advance:]
+	}
+}
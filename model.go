@@ -0,0 +1,185 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"robpike.io/cmd/typo/trigram"
+)
+
+// A model is a digram and trigram table computed once, by typo train, from a
+// large body of text. With -model, the document's own counts are added to
+// the model's before scoring, so even a short document is judged against
+// plenty of text.
+
+// With -corpus, the statistics come from the corpus alone and the input is
+// scored against them without contributing to them.
+
+var (
+	modelFile  = flag.String("model", "", "add the statistics in the model `file` to those of the input")
+	corpusPath = flag.String("corpus", "", "score the input against the statistics of the `files` in this file or directory alone")
+)
+
+// model holds the table loaded from -model, if any.
+var model *trigram.Table
+
+// corpus holds the reference table built from -corpus, if any.
+var corpus *trigram.Table
+
+// loadModel reads the model named by -model.
+func loadModel() {
+	if *modelFile == "" {
+		return
+	}
+	f, err := os.Open(*modelFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		os.Exit(2)
+	}
+	defer f.Close()
+	model, err = trigram.Read(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: reading model %s: %s\n", *modelFile, err)
+		os.Exit(2)
+	}
+	logf("model: %s", *modelFile)
+	table = newTable()
+}
+
+// loadCorpus builds the reference table from the files named by -corpus,
+// including the model's counts if there is a model.
+func loadCorpus() {
+	if *corpusPath == "" {
+		return
+	}
+	corpusFiles := walk([]string{*corpusPath})
+	for _, file := range corpusFiles {
+		add(file, nil)
+	}
+	logf("corpus: %d words in %d files from %s", len(words), len(corpusFiles), *corpusPath)
+	t := newTable()
+	for _, word := range words {
+		if !word.ignore {
+			t.Add(form(word.text()))
+		}
+	}
+	t.SetReference(true)
+	words = words[:0]
+	corpus = t
+	table = t
+}
+
+// newTable returns the table to which the input's counts are to be added:
+// one holding the counts of the model and of the word-list model, if they
+// are in use. If there is a
+// corpus, it returns the corpus table, to which nothing should be added.
+func newTable() *trigram.Table {
+	if corpus != nil {
+		return corpus
+	}
+	t := trigram.New()
+	t.SetCombination(combination())
+	t.SetZero(zeroHandling())
+	if model != nil {
+		t.Merge(model)
+	}
+	if wordlist != nil {
+		t.Merge(wordlist)
+	}
+	return t
+}
+
+// train implements the train subcommand:
+//
+//	typo train [-o model.bin] [flags] corpus...
+//
+// It computes the statistics of the corpus, which is a list of files and
+// directories to walk, and writes them to the output file for use with
+// -model. Only the flags that control the input, such as -html, apply.
+func train(args []string) {
+	fs := commandFlags("train")
+	out := fs.String("o", "model.bin", "write the model to `file`")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	setEncoding()
+	setKeys()
+	checkPo()
+	setCSVCols()
+	setJSONPath()
+	loadStopWords()
+	for _, file := range walk(fs.Args()) {
+		add(file, nil)
+	}
+	t := trigram.New()
+	for _, word := range words {
+		if !word.ignore {
+			t.Add(form(word.text()))
+		}
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		os.Exit(2)
+	}
+	if err := t.Write(f); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: writing %s: %s\n", *out, err)
+		os.Exit(2)
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		os.Exit(2)
+	}
+}
+
+// walk returns the names of the files in the list, replacing each directory
+// by the regular files beneath it. Unless -no-gitignore is set, .git
+// directories and the files excluded by .gitignore files are left out.
+func walk(paths []string) []string {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			files = append(files, path) // Let add report any error.
+			continue
+		}
+		var ignore *gitignore
+		if !*noGitignore {
+			ignore = newGitignore(path)
+		}
+		root, _ := filepath.Abs(path)
+		filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "typo: warning: %s\n", err)
+				return nil
+			}
+			if ignore != nil {
+				rel, _ := filepath.Rel(path, file)
+				abs := filepath.Join(root, rel)
+				if file != path && (d.IsDir() && d.Name() == ".git" || ignore.ignored(abs, d.IsDir())) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.IsDir() {
+					ignore.load(abs)
+				}
+			}
+			if d.Type().IsRegular() {
+				files = append(files, file)
+			}
+			return nil
+		})
+	}
+	return files
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// With -ocr, typo looks for the errors of optical character recognition,
+// which confuses rn and m, cl and d, l and 1, O and 0, and the like. An
+// unlikely word that becomes a known word when one such confusion is undone
+// is reported, whatever its score, with a note naming the known word, which
+// is also offered first as the correction.
+
+var ocrMode = flag.Bool("ocr", false, "report words that become known words when a common OCR confusion, such as rn for m, is undone")
+
+// ocrConfusions lists the pairs of strings OCR mistakes for each other.
+// Each is tried in both directions.
+var ocrConfusions = [][2]string{
+	{"rn", "m"},
+	{"cl", "d"},
+	{"l", "1"},
+	{"I", "1"},
+	{"I", "l"},
+	{"o", "0"},
+	{"O", "0"},
+	{"vv", "w"},
+	{"li", "h"},
+	{"ii", "u"},
+	{"c", "e"},
+	{"S", "5"},
+	{"B", "8"},
+}
+
+// ocrReading returns the known word that the word becomes when one OCR
+// confusion in it is undone, or the empty string if there is none.
+func ocrReading(word string) string {
+	for _, pair := range ocrConfusions {
+		for _, p := range [][2]string{{pair[0], pair[1]}, {pair[1], pair[0]}} {
+			from, to := p[0], p[1]
+			for i := 0; ; {
+				j := strings.Index(word[i:], from)
+				if j < 0 {
+					break
+				}
+				i += j
+				s := word[:i] + to + word[i+len(from):]
+				if isKnown(fold(s)) {
+					return s
+				}
+				i += len(from)
+			}
+		}
+	}
+	return ""
+}
+
+// ocrNote returns the note on the word if -ocr is set and it looks like an
+// OCR error, or the empty string.
+func ocrNote(word string) string {
+	if !*ocrMode {
+		return ""
+	}
+	if s := ocrReading(word); s != "" {
+		return fmt.Sprintf("likely OCR error for '%s'", s)
+	}
+	return ""
+}
+
+// addOCRErrors returns the list, which holds the words at or above the
+// threshold, with the words of the rest that look like OCR errors, if
+// -ocr is set, after them.
+func addOCRErrors(list, rest []*Word) []*Word {
+	if !*ocrMode {
+		return list
+	}
+	list = list[:len(list):len(list)] // Do not overwrite rest.
+	for _, w := range rest {
+		if ocrReading(w.text()) != "" {
+			list = append(list, w)
+		}
+	}
+	return list
+}
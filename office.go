@@ -0,0 +1,120 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Word processor documents, .docx (Office Open XML) and .odt (OpenDocument),
+// are zip archives holding the text as XML. The text of each paragraph is
+// extracted as a line, so a word's location in such a document is given
+// as file:paragraph:column.
+
+// officeParts maps a document suffix to the archive member holding its text.
+var officeParts = map[string]string{
+	".docx": "word/document.xml",
+	".odt":  "content.xml",
+}
+
+// addOffice adds the words of the file if it is a .docx or .odt document,
+// and reports whether it did.
+func addOffice(file string) bool {
+	suffix := strings.ToLower(filepath.Ext(file))
+	part, ok := officeParts[suffix]
+	if !ok {
+		return false
+	}
+	z, err := zip.OpenReader(file)
+	if err != nil {
+		return false // Let the caller report the error.
+	}
+	defer z.Close()
+	rc, err := z.Open(part)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s: %s\n", file, err)
+		failed = true
+		return true
+	}
+	defer rc.Close()
+	text, err := officeText(rc, suffix == ".docx")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s: %s\n", file, err)
+		failed = true
+		return true
+	}
+	// Show context from the paragraphs, not the raw archive.
+	fileLines[file] = strings.Split(text, "\n")
+	add(file, strings.NewReader(text))
+	return true
+}
+
+// officeText returns the text of the XML document, one paragraph per line.
+// In a .docx document, text lives in w:t elements within w:p paragraphs;
+// in an .odt document, it is all the character data within text:p and
+// text:h paragraphs.
+func officeText(r io.Reader, docx bool) (string, error) {
+	d := xml.NewDecoder(r)
+	var text, para strings.Builder
+	depth := 0 // Depth of paragraph nesting.
+	inText := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p", "h":
+				depth++
+			case "t":
+				inText = true
+			case "tab":
+				para.WriteByte('\t')
+			case "br", "cr", "line-break":
+				para.WriteByte(' ')
+			case "s":
+				// ODF's run of spaces; the c attribute counts them.
+				n := 1
+				for _, a := range t.Attr {
+					if a.Name.Local == "c" {
+						if c, err := strconv.Atoi(a.Value); err == nil {
+							n = c
+						}
+					}
+				}
+				para.WriteString(strings.Repeat(" ", n))
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p", "h":
+				if depth > 0 {
+					depth--
+				}
+				text.WriteString(para.String())
+				text.WriteByte('\n')
+				para.Reset()
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if depth > 0 && (inText || !docx) {
+				para.WriteString(strings.ReplaceAll(string(t), "\n", " "))
+			}
+		}
+	}
+	return text.String(), nil
+}
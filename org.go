@@ -0,0 +1,98 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"regexp"
+	"strings"
+)
+
+var orgMode = flag.Bool("org", false, "skip Org mode markup: source and example blocks, drawers, and link targets")
+
+func init() {
+	addFilter(filter{name: "org", on: func() bool { return *orgMode }, markup: orgFilter})
+}
+
+var (
+	// #+BEGIN_SRC go, #+begin_example, and the like.
+	orgBegin = regexp.MustCompile(`(?i)^\s*#\+begin_(\w+)`)
+	// #+TITLE: text and other keywords.
+	orgKeyword = regexp.MustCompile(`(?i)^\s*#\+(\w+):`)
+	// :PROPERTIES:, :LOGBOOK:, and other drawers.
+	orgDrawer = regexp.MustCompile(`^\s*:[\w-]+:\s*$`)
+	// * TODO [#A] Headline :tag:
+	orgHeadline = regexp.MustCompile(`^\*+\s+(?:(?:TODO|DONE)\s+)?(?:\[#\w\]\s+)?`)
+	orgTags     = regexp.MustCompile(`\s(:[\w@#%:]+:)\s*$`)
+
+	orgInline = []*regexp.Regexp{
+		regexp.MustCompile(`\[\[[^\]]*\]\[([^\]]*)\]\]`),  // [[target][description]]
+		regexp.MustCompile(`\[\[[^\]]*\]\]`),              // [[target]]
+		regexp.MustCompile(`(^|\s)[~=][^\s~=][^~=]*[~=]`), // ~code~ and =verbatim=
+	}
+)
+
+// orgProse lists the blocks whose contents are prose to be checked.
+var orgProse = map[string]bool{
+	"center": true,
+	"quote":  true,
+	"verse":  true,
+}
+
+// orgTitles lists the keywords whose values are prose.
+var orgTitles = map[string]bool{
+	"author":   true,
+	"caption":  true,
+	"subtitle": true,
+	"title":    true,
+}
+
+// orgFilter returns a filter that blanks out Org mode markup: source,
+// example, and export blocks; drawers such as :PROPERTIES:; comments and
+// keywords other than titles; headline stars, TODO keywords, and tags;
+// link targets; and code and verbatim text. Headlines and paragraphs remain.
+func orgFilter() lineFilter {
+	end := "" // The line that ends the block or drawer being skipped.
+	return func(line string) string {
+		trimmed := strings.ToLower(strings.TrimSpace(line))
+		if end != "" {
+			if trimmed == end || strings.HasPrefix(trimmed, end+" ") {
+				end = ""
+			}
+			return blank(line, 0, len(line))
+		}
+		if m := orgBegin.FindStringSubmatch(line); m != nil {
+			if kind := strings.ToLower(m[1]); !orgProse[kind] {
+				end = "#+end_" + kind
+			}
+			return blank(line, 0, len(line))
+		}
+		if m := orgKeyword.FindStringSubmatchIndex(line); m != nil {
+			if orgTitles[strings.ToLower(line[m[2]:m[3]])] {
+				return blank(line, 0, m[1])
+			}
+			return blank(line, 0, len(line))
+		}
+		switch {
+		case orgDrawer.MatchString(line):
+			if trimmed != ":end:" {
+				end = ":end:"
+			}
+			return blank(line, 0, len(line))
+		case trimmed == "#" || strings.HasPrefix(trimmed, "# "), strings.HasPrefix(trimmed, "#+"):
+			return blank(line, 0, len(line))
+		}
+		if m := orgHeadline.FindStringIndex(line); m != nil {
+			line = blank(line, 0, m[1])
+			if t := orgTags.FindStringSubmatchIndex(line); t != nil {
+				line = blank(line, t[2], t[3])
+			}
+		}
+		for _, re := range orgInline {
+			line = blankMatches(line, re)
+		}
+		return line
+	}
+}
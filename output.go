@@ -0,0 +1,444 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	format    = flag.String("format", "text", "report `format`: text, html, csv, tsv, github, quickfix, json, or sqlite")
+	outFile   = flag.String("o", "", "write the report to `file` instead of standard output")
+	colorMode = flag.String("color", "auto", "show findings in context, in color: auto (if a terminal), always, or never")
+	listFiles = flag.Bool("l", false, "list only the names of the files with findings, one per line")
+	print0    = flag.Bool("print0", false, "print each finding as NUL-terminated fields, file, line, col, kind, score, severity, and word, followed by another NUL")
+)
+
+// out is where the report goes.
+var out = os.Stdout
+
+// openOutput checks the -format flag and opens the -o file, if any.
+func openOutput() {
+	switch *format {
+	case "text", "html", "csv", "tsv", "github", "quickfix", "json":
+	case "sqlite":
+		if *outFile == "" {
+			fmt.Fprintf(os.Stderr, "typo: -format=sqlite needs -o to name the database\n")
+			os.Exit(2)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "typo: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	if *listFiles && *format != "text" {
+		fmt.Fprintf(os.Stderr, "typo: -l and -format=%s are exclusive\n", *format)
+		os.Exit(2)
+	}
+	if *print0 && *format != "text" {
+		fmt.Fprintf(os.Stderr, "typo: -print0 and -format=%s are exclusive\n", *format)
+		os.Exit(2)
+	}
+	parseTemplate()
+	if *outFile == "" || *format == "sqlite" {
+		return
+	}
+	f, err := os.Create(*outFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		os.Exit(2)
+	}
+	out = f
+}
+
+// closeOutput closes the -o file, if any.
+func closeOutput() {
+	if out == os.Stdout {
+		return
+	}
+	if err := out.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		os.Exit(2)
+	}
+}
+
+// report writes the repeated words and the most unlikely -n of the unlikely
+// words, in the order selected by -sort, in the selected format.
+func report(reps, list []*Word) {
+	if *listFiles {
+		// Every file with a finding, not just those among the first -n.
+		writeFileList(reps, list)
+		return
+	}
+	list = capPerFile(list)
+	list = order(list[:min(len(list), *nTypos)])
+	if *allLocs && !*columns {
+		list = occurrences(list)
+		if *sortOrder == "location" {
+			sort.Sort(ByLocation(list))
+		}
+	}
+	if findingTemplate != nil {
+		writeTemplate(reps, list)
+		return
+	}
+	if *print0 {
+		writePrint0(reps, list)
+		return
+	}
+	switch *format {
+	case "text":
+		if *groupFiles && !*columns {
+			printByFile(reps, list)
+			return
+		}
+		for _, w := range reps {
+			printFinding(w, repeatSuffix(w))
+		}
+		for _, w := range confused {
+			printConfusable(w)
+		}
+		for _, m := range mixed {
+			printFinding(m.british, " ("+m.note()+")")
+		}
+		spell(list)
+	case "html":
+		writeHTML(reps, list)
+	case "csv", "tsv":
+		writeCSV(reps, list)
+	case "github":
+		writeGitHub(reps, list)
+	case "quickfix":
+		writeQuickfix(reps, list)
+	case "json":
+		writeJSON(reps, list)
+	case "sqlite":
+		writeSQLite(reps, list)
+	}
+}
+
+// writeJSON writes the findings as JSON, in the form of the -serve response
+// and of baselines, for typo report to merge and compare.
+func writeJSON(reps, list []*Word) {
+	findings := []jsonFinding{}
+	add := func(w *Word, kind, note string) {
+		findings = append(findings, jsonFinding{
+			Kind:     kind,
+			File:     w.file,
+			Line:     w.lineNum,
+			Col:      w.col,
+			Word:     w.text(),
+			Score:    int(w.score),
+			Severity: severity(w, kind),
+			Note:     note,
+		})
+	}
+	for _, w := range reps {
+		add(w, "repeat", repeatNote(w))
+	}
+	for _, w := range confused {
+		add(w, "confusable", confusableNote(w))
+	}
+	for _, m := range mixed {
+		add(m.british, "variant", m.note())
+		add(m.american, "variant", m.note())
+	}
+	for _, w := range list {
+		add(w, "typo", typoNote(w))
+		if *showCounts {
+			findings[len(findings)-1].Count = count(w)
+		}
+	}
+	if err := encodeFindings(out, findings); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: writing json: %s\n", err)
+		os.Exit(2)
+	}
+}
+
+// writeFileList writes the names of the files with findings, sorted,
+// each followed by a newline or, with -print0, a NUL.
+func writeFileList(reps, list []*Word) {
+	seen := make(map[string]bool)
+	for _, l := range [][]*Word{reps, confused, list} {
+		for _, w := range l {
+			seen[w.file] = true
+		}
+	}
+	for _, m := range mixed {
+		seen[m.british.file] = true
+		seen[m.american.file] = true
+	}
+	var files []string
+	for file := range seen {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	end := "\n"
+	if *print0 {
+		end = "\x00"
+	}
+	for _, file := range files {
+		fmt.Fprint(out, file, end)
+	}
+}
+
+// writePrint0 writes the findings for -print0: each field ends with a NUL,
+// and each finding with another, so that no file name can confuse the
+// reader. No field is empty.
+func writePrint0(reps, list []*Word) {
+	record := func(w *Word, kind string) {
+		for _, f := range []string{w.file, strconv.Itoa(w.lineNum), strconv.Itoa(w.col), kind, strconv.Itoa(int(w.score)), severity(w, kind), w.text()} {
+			fmt.Fprintf(out, "%s\x00", f)
+		}
+		fmt.Fprint(out, "\x00")
+	}
+	for _, w := range reps {
+		record(w, "repeat")
+	}
+	for _, w := range confused {
+		record(w, "confusable")
+	}
+	for _, m := range mixed {
+		record(m.british, "variant")
+		record(m.american, "variant")
+	}
+	for _, w := range list {
+		record(w, "typo")
+	}
+}
+
+// typoNote returns the notes on the unlikely word, without parentheses,
+// for the formats that give them a field of their own.
+func typoNote(w *Word) string {
+	var notes []string
+	if d := diagnosisText(w); d != "" {
+		notes = append(notes, d)
+	}
+	if n := lineNoteText(w); n != "" {
+		notes = append(notes, n)
+	}
+	return strings.Join(notes, "; ")
+}
+
+// capPerFile returns the words of the list, most unlikely first, without
+// those beyond the first -n-per-file of each file.
+func capPerFile(list []*Word) []*Word {
+	if *nPerFile <= 0 {
+		return list
+	}
+	count := make(map[string]int)
+	var capped []*Word
+	for _, w := range list {
+		if count[w.file] < *nPerFile {
+			count[w.file]++
+			capped = append(capped, w)
+		}
+	}
+	return capped
+}
+
+// writeGitHub writes the findings as GitHub Actions workflow commands,
+// which annotate the lines in pull requests.
+func writeGitHub(reps, list []*Word) {
+	for _, w := range reps {
+		fmt.Fprintf(out, "::notice file=%s,line=%d,col=%d::%s\n", githubProperty(w.file), w.lineNum, w.col,
+			githubData(fmt.Sprintf("repeated word '%s'%s", w.text(), strings.TrimPrefix(repeatSuffix(w), " repeats"))))
+	}
+	for _, w := range confused {
+		fmt.Fprintf(out, "::notice file=%s,line=%d,col=%d::%s\n", githubProperty(w.file), w.lineNum, w.col,
+			githubData(fmt.Sprintf("'%s' %s", w.text(), confusableNote(w))))
+	}
+	for _, m := range mixed {
+		w := m.british
+		fmt.Fprintf(out, "::notice file=%s,line=%d,col=%d::%s\n", githubProperty(w.file), w.lineNum, w.col,
+			githubData(fmt.Sprintf("'%s' %s", w.text(), m.note())))
+	}
+	for _, w := range list {
+		fmt.Fprintf(out, "::%s file=%s,line=%d,col=%d::%s\n", githubLevel(severity(w, "typo")), githubProperty(w.file), w.lineNum, w.col,
+			githubData(fmt.Sprintf("possible typo '%s' (score %d)%s", w.text(), int(w.score), diagnosis(w))))
+	}
+}
+
+// writeQuickfix writes the findings one to a line in the form
+// file:line:col: severity: message, which the default error formats of
+// Vim's quickfix list and Emacs's compilation mode recognize.
+func writeQuickfix(reps, list []*Word) {
+	line := func(w *Word, kind, message string) {
+		fmt.Fprintf(out, "%s:%d:%d: %s: %s\n", w.file, w.lineNum, w.col, severity(w, kind), strings.ReplaceAll(message, "\n", " "))
+	}
+	for _, w := range reps {
+		line(w, "repeat", fmt.Sprintf("repeated word '%s'%s", w.text(), strings.TrimPrefix(repeatSuffix(w), " repeats")))
+	}
+	for _, w := range confused {
+		line(w, "confusable", fmt.Sprintf("'%s' %s", w.text(), confusableNote(w)))
+	}
+	for _, m := range mixed {
+		line(m.british, "variant", fmt.Sprintf("'%s' %s", m.british.text(), m.note()))
+	}
+	for _, w := range list {
+		line(w, "typo", fmt.Sprintf("possible typo '%s' (score %d)%s%s", w.text(), int(w.score), diagnosis(w), lineNote(w)))
+	}
+}
+
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// githubLevel returns the workflow command for the severity.
+func githubLevel(severity string) string {
+	if severity == "info" {
+		return "notice"
+	}
+	return severity
+}
+
+// githubData escapes the message of a workflow command.
+func githubData(s string) string {
+	return githubDataEscaper.Replace(s)
+}
+
+// githubProperty escapes a property value of a workflow command.
+func githubProperty(s string) string {
+	return githubPropertyEscaper.Replace(s)
+}
+
+// printByFile prints the findings in order of location, with a header line
+// before those of each file.
+func printByFile(reps, list []*Word) {
+	type finding struct {
+		w      *Word
+		suffix string
+	}
+	var all []finding
+	for _, w := range reps {
+		all = append(all, finding{w, repeatSuffix(w)})
+	}
+	for _, w := range confused {
+		all = append(all, finding{w, " (" + confusableNote(w) + ")"})
+	}
+	for _, m := range mixed {
+		all = append(all, finding{m.british, " (" + m.note() + ")"})
+	}
+	for _, w := range list {
+		all = append(all, finding{w, diagnosis(w) + lineNote(w)})
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return before(all[i].w, all[j].w)
+	})
+	file := ""
+	for i, f := range all {
+		if i == 0 || f.w.file != file {
+			file = f.w.file
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "== %s ==\n", file)
+		}
+		printFinding(f.w, f.suffix)
+	}
+}
+
+// writeCSV writes the findings as comma- or tab-separated values, one row
+// per finding, after a header row naming the columns.
+func writeCSV(reps, list []*Word) {
+	w := csv.NewWriter(out)
+	if *format == "tsv" {
+		w.Comma = '\t'
+	}
+	w.Write([]string{"file", "line", "col", "score", "kind", "word", "severity"})
+	row := func(word *Word, kind string) {
+		w.Write([]string{
+			word.file,
+			strconv.Itoa(word.lineNum),
+			strconv.Itoa(word.col),
+			strconv.Itoa(int(word.score)),
+			kind,
+			word.text(),
+			severity(word, kind),
+		})
+	}
+	for _, word := range reps {
+		row(word, "repeat")
+	}
+	for _, word := range confused {
+		row(word, "confusable")
+	}
+	for _, m := range mixed {
+		row(m.british, "variant")
+		row(m.american, "variant")
+	}
+	for _, word := range list {
+		row(word, "typo")
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: writing %s: %s\n", *format, err)
+		os.Exit(2)
+	}
+}
+
+// useColor records whether to print in color.
+var useColor bool
+
+// ANSI terminal escape sequences.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiRed     = "\x1b[31m"
+	ansiYellow  = "\x1b[33m"
+	ansiCyan    = "\x1b[36m"
+	ansiReverse = "\x1b[7m"
+)
+
+// setColor sets useColor according to -color.
+func setColor() {
+	switch *colorMode {
+	case "always":
+		useColor = true
+	case "never":
+		useColor = false
+	case "auto":
+		info, err := out.Stat()
+		useColor = err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+	default:
+		fmt.Fprintf(os.Stderr, "typo: unknown color mode %q\n", *colorMode)
+		os.Exit(2)
+	}
+}
+
+// severityColors are the colors of the scores of findings of each severity.
+var severityColors = map[string]string{
+	"error":   ansiRed,
+	"warning": ansiYellow,
+	"info":    ansiCyan,
+}
+
+// printFinding prints the word, followed by the suffix. In color, the score
+// is colored by its severity, and the line the word came from
+// is printed beneath with the word highlighted.
+func printFinding(w *Word, suffix string) {
+	if !useColor {
+		fmt.Fprintf(out, "%s%s\n", w, suffix)
+		return
+	}
+	if w.score == 0 {
+		fmt.Fprintf(out, "%s:%d:%d %s%s%s%s\n", w.file, w.lineNum, w.col, ansiBold, w.text(), ansiReset, suffix)
+	} else {
+		sev := severity(w, "typo")
+		fmt.Fprintf(out, "%s:%d:%d %s [%s%d%s] %s%s%s%s%s\n", w.file, w.lineNum, w.col, sev, severityColors[sev], int(w.score), ansiReset, countPrefix(w), ansiBold, w.text(), ansiReset, suffix)
+	}
+	line := fileLine(w.file, w.lineNum)
+	i := w.byteNum - 1
+	if i+len(w.text()) > len(line) || line[i:i+len(w.text())] != w.text() {
+		return // Can't find it; perhaps the input was standard input.
+	}
+	fmt.Fprintf(out, "\t%s%s%s%s%s\n", strings.TrimLeft(line[:i], " \t"), ansiReverse, w.text(), ansiReset, line[i+len(w.text()):])
+}
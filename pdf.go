@@ -0,0 +1,60 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// With -pdf, the text of PDF files is extracted by the pdftotext command,
+// part of Poppler, and each page is scanned as a separate piece of text
+// named file.pdf:page N, so a word is reported as file.pdf:page 7:12:5.
+
+var pdfMode = flag.Bool("pdf", false, "extract the text of PDF files with pdftotext, reporting locations by page")
+
+func init() {
+	addFilter(filter{name: "pdf", on: func() bool { return *pdfMode }})
+}
+
+var pdfMagic = []byte("%PDF-")
+
+// addPDF adds the words of the file if it is a PDF file and -pdf is set,
+// and reports whether it did.
+func addPDF(file string) bool {
+	if !*pdfMode {
+		return false
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return false // Let the caller report the error.
+	}
+	magic := make([]byte, len(pdfMagic))
+	_, err = io.ReadFull(f, magic)
+	f.Close()
+	if err != nil || !bytes.Equal(magic, pdfMagic) {
+		return false
+	}
+	cmd := exec.Command("pdftotext", "-enc", "UTF-8", file, "-")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	text, err := cmd.Output()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: pdftotext %s: %v: %s\n", file, err, bytes.TrimSpace(stderr.Bytes()))
+		failed = true
+		return true
+	}
+	// Pages are separated by form feeds.
+	for i, page := range bytes.Split(text, []byte("\f")) {
+		if len(bytes.TrimSpace(page)) > 0 {
+			add(fmt.Sprintf("%s:page %d", file, i+1), bytes.NewReader(page))
+		}
+	}
+	return true
+}
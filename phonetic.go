@@ -0,0 +1,74 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+// phonetic indexes the known words by their Soundex codes, so words that
+// sound alike can be suggested even if they are spelled quite differently.
+// It is built when first needed.
+var phonetic map[string][]string
+
+// soundsLike returns the known words with the same Soundex code as the word.
+func soundsLike(word string) []string {
+	if phonetic == nil {
+		phonetic = make(map[string][]string)
+		for k := range known {
+			if code := soundex(k); code != "" {
+				phonetic[code] = append(phonetic[code], k)
+			}
+		}
+	}
+	return phonetic[soundex(word)]
+}
+
+// soundexDigits gives the digit for each letter; 0 marks the vowels and
+// the letters h, w, and y, which are not coded.
+const soundexDigits = "01230120022455012623010202" // a to z
+
+// soundex returns the American Soundex code of the word, its first letter
+// followed by three digits coding the consonants that follow, or the empty
+// string if the word does not begin with an ASCII letter. Other runes are
+// ignored.
+func soundex(word string) string {
+	code := make([]byte, 0, 4)
+	var last byte
+	for _, r := range word {
+		if 'A' <= r && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		if r < 'a' || 'z' < r {
+			if len(code) == 0 {
+				return ""
+			}
+			continue
+		}
+		d := soundexDigits[r-'a']
+		switch {
+		case len(code) == 0:
+			code = append(code, byte(r)-'a'+'A')
+		case d != '0' && d != last:
+			code = append(code, d)
+		}
+		// H and W do not separate letters with the same code; vowels do.
+		if r != 'h' && r != 'w' {
+			last = d
+		}
+		if len(code) == 4 {
+			break
+		}
+	}
+	if len(code) == 0 {
+		return ""
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
+
+// maxPhoneticDistance returns how far a word that sounds like the word, of
+// length n runes, may be from it to be suggested.
+func maxPhoneticDistance(n int) int {
+	return max(maxEditDistance+1, n/3)
+}
@@ -0,0 +1,92 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var poMode = flag.String("po", "", "check only the `strings` of gettext .po and .pot files: msgid, msgstr, or both")
+
+func init() {
+	addFilter(filter{name: "po", on: func() bool { return *poMode != "" }, selects: poFilter})
+}
+
+// checkPo checks -po.
+func checkPo() {
+	switch *poMode {
+	case "", "msgid", "msgstr", "both":
+	default:
+		fmt.Fprintf(os.Stderr, "typo: unknown -po strings %q; want msgid, msgstr, or both\n", *poMode)
+		os.Exit(2)
+	}
+}
+
+// printfVerb matches the printf verbs, such as %s, %1$d, and %@, common in
+// the strings of message catalogs.
+var printfVerb = regexp.MustCompile(`%(?:[0-9]+\$)?[-+# 0]*[0-9]*(?:\.[0-9]+)?(?:[hlqLjzt]+)?[a-zA-Z@%]`)
+
+// poFilter returns a filter that blanks out all but the selected strings
+// of the gettext catalog, or nil if the file is not one. It records the
+// source references of the entries as line notes.
+func poFilter(file string) lineFilter {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".po", ".pot":
+	default:
+		return nil
+	}
+	refs := make(map[int][]textNote)
+	lineNotes[file] = refs
+	lineNum := 0
+	ref := ""      // The references of the current entry.
+	inMsg := false // Whether the entry's strings have begun.
+	keep := false  // Whether the strings being read are to be checked.
+	first := true  // Whether the entry is the first, which may be the header.
+	header := false
+	return func(line string) string {
+		lineNum++
+		v := newValueLine(line)
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "#:"):
+			if inMsg {
+				ref, inMsg = "", false
+			}
+			ref = strings.TrimSpace(ref + " " + strings.TrimSpace(trimmed[2:]))
+			v.blank(0, len(line))
+			return v.String()
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			v.blank(0, len(line))
+			return v.String()
+		case strings.HasPrefix(trimmed, "msgctxt"):
+			keep = false
+		case strings.HasPrefix(trimmed, "msgid"):
+			keep = *poMode == "msgid" || *poMode == "both"
+			header = first && trimmed == `msgid ""`
+			first = false
+		case strings.HasPrefix(trimmed, "msgstr"):
+			// The header's msgstr holds metadata, not prose.
+			keep = (*poMode == "msgstr" || *poMode == "both") && !header
+		}
+		inMsg = true
+		i := strings.IndexByte(line, '"')
+		if i < 0 {
+			v.blank(0, len(line))
+			return v.String()
+		}
+		v.blank(0, i)
+		end, _ := v.quoted(i, `"`, keep)
+		v.blank(end, len(line))
+		if keep && ref != "" {
+			refs[lineNum] = []textNote{{1, "#: " + ref}}
+		}
+		return blankMatches(v.String(), printfVerb)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// With -repeats-only, typo reports only repeated words. It keeps no words
+// and computes no statistics, so it is quick even on huge inputs.
+//
+// With -near-repeats, a word is also reported as a repeat if it repeats
+// one no more than that many words back, as in "in the in", a common
+// leftover of editing. Idioms such as "side by side" are let be.
+
+var (
+	repeatsOnly = flag.Bool("repeats-only", false, "report only repeated words, skipping the statistics")
+	nearRepeats = flag.Int("near-repeats", 0, "also report words repeated with up to `N` words between, as in \"in the in\"")
+)
+
+// idiomWords are the words that, between two of the same word, make an
+// idiom, as in side by side and more and more, not a near repeat.
+var idiomWords = map[string]bool{
+	"after":   true,
+	"against": true,
+	"and":     true,
+	"by":      true,
+	"for":     true,
+	"in":      true,
+	"on":      true,
+	"or":      true,
+	"over":    true,
+	"to":      true,
+	"upon":    true,
+}
+
+// A wordLoc is the location of a word.
+type wordLoc struct {
+	file             string
+	lineNum, byteNum int
+}
+
+// repeatGaps holds, by location, the number of words between each near
+// repeat and the word it repeats.
+var repeatGaps = make(map[wordLoc]int)
+
+// recent holds the folded words most recently read, the last one last.
+type recent []string
+
+// repeats reports whether the word repeats one of the recent words, and
+// records it among them.
+func (r *recent) repeats(w *Word) bool {
+	return r.repeatsAt(w.lower(), wordLoc{w.file, w.lineNum, w.byteNum})
+}
+
+// repeatsAt is repeats for the word, in lower case, at the location.
+func (r *recent) repeatsAt(lower string, loc wordLoc) bool {
+	gap := -1
+	n := len(*r)
+	for g := 0; g < n; g++ {
+		if (*r)[n-1-g] == lower {
+			gap = g
+			break
+		}
+	}
+	if gap == 1 && idiomWords[(*r)[n-1]] {
+		gap = -1
+	}
+	if len(*r) > *nearRepeats {
+		copy(*r, (*r)[1:])
+		*r = (*r)[:len(*r)-1]
+	}
+	*r = append(*r, lower)
+	if gap > 0 {
+		repeatGaps[loc] = gap
+	}
+	return gap >= 0
+}
+
+// repeatNote returns the note on a repeat: how many words lie between it
+// and the word it repeats, if any.
+func repeatNote(w *Word) string {
+	switch gap := repeatGaps[wordLoc{w.file, w.lineNum, w.byteNum}]; gap {
+	case 0:
+		return ""
+	case 1:
+		return "1 word between"
+	default:
+		return fmt.Sprintf("%d words between", gap)
+	}
+}
+
+// repeatSuffix returns the suffix with which to print a repeat.
+func repeatSuffix(w *Word) string {
+	if note := repeatNote(w); note != "" {
+		return " repeats (" + note + ")"
+	}
+	return " repeats"
+}
+
+// checkRepeatsOnly checks that -repeats-only is not combined with -r.
+func checkRepeatsOnly() {
+	if *repeatsOnly && *noRepeats {
+		fmt.Fprintf(os.Stderr, "typo: -repeats-only and -r are exclusive\n")
+		os.Exit(2)
+	}
+}
+
+// scanRepeats returns the repeats in the words already read, from standard
+// input or the index, and in the files, whose words it does not keep.
+func scanRepeats(files []string) []*Word {
+	var reps []*Word
+	var r recent
+	for _, w := range words {
+		if r.repeats(w) && inDiff(w) && !suppressed(w) {
+			reps = append(reps, w)
+		}
+	}
+	lowMemWord = func(text, line, file string, lineNum, byteNum int) {
+		if !r.repeatsAt(lowerCase(text), wordLoc{file, lineNum, byteNum}) {
+			return
+		}
+		if w := newOwnWord(text, file, lineNum, byteNum, column(line, byteNum)); inDiff(w) && !suppressed(w) {
+			reps = append(reps, w)
+		} else {
+			w.release()
+		}
+	}
+	for _, f := range files {
+		add(f, nil)
+	}
+	lowMemWord = nil
+	words = words[:0]
+	return reps
+}
@@ -0,0 +1,166 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// The report subcommand works on reports written with -format=json, or
+// with -write-baseline:
+//
+//	typo report merge a.json b.json...
+//	typo report diff old.json new.json
+//
+// Merge combines the reports, dropping duplicates, and writes the result
+// as JSON. Diff prints the findings introduced in the new report, marked
+// +, and those fixed since the old, marked -, and exits with status 1 if
+// any were introduced; with -format=json it writes them as the lists
+// "introduced" and "fixed". As with -baseline, findings are matched by
+// kind, file, and word, so edits elsewhere in a file do not disturb them.
+
+// reportCmd implements the report subcommand.
+func reportCmd(args []string) {
+	if len(args) == 0 {
+		reportUsage()
+	}
+	switch args[0] {
+	case "merge":
+		if len(args) < 2 {
+			reportUsage()
+		}
+		var all []jsonFinding
+		for _, file := range args[1:] {
+			all = append(all, loadFindings(file)...)
+		}
+		openOutput()
+		if err := encodeFindings(out, mergeFindings(all)); err != nil {
+			fmt.Fprintf(os.Stderr, "typo: writing json: %s\n", err)
+			os.Exit(2)
+		}
+		closeOutput()
+	case "diff":
+		if len(args) != 3 {
+			reportUsage()
+		}
+		introduced, fixed := diffFindings(loadFindings(args[1]), loadFindings(args[2]))
+		openOutput()
+		if *format == "json" {
+			err := encodeJSON(out, map[string][]jsonFinding{"introduced": introduced, "fixed": fixed})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "typo: writing json: %s\n", err)
+				os.Exit(2)
+			}
+		} else {
+			for _, f := range introduced {
+				fmt.Fprintf(out, "+ %s\n", findingString(f))
+			}
+			for _, f := range fixed {
+				fmt.Fprintf(out, "- %s\n", findingString(f))
+			}
+		}
+		closeOutput()
+		if len(introduced) > 0 {
+			os.Exit(1)
+		}
+	default:
+		reportUsage()
+	}
+}
+
+func reportUsage() {
+	fmt.Fprintf(os.Stderr, "usage: typo report merge a.json b.json...\n       typo report diff old.json new.json\n")
+	os.Exit(2)
+}
+
+// loadFindings returns the findings in the file, exiting if it cannot be read.
+func loadFindings(file string) []jsonFinding {
+	findings, err := readFindings(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		os.Exit(2)
+	}
+	return findings
+}
+
+// mergeFindings returns the findings in order of location, without
+// duplicates: findings of the same kind for the same word at the same
+// place, of which the first is kept, as scores differ from run to run.
+func mergeFindings(all []jsonFinding) []jsonFinding {
+	type key struct {
+		kind, file, word string
+		line, col        int
+	}
+	merged := []jsonFinding{}
+	seen := make(map[key]bool)
+	for _, f := range all {
+		k := key{f.Kind, f.File, f.Word, f.Line, f.Col}
+		if !seen[k] {
+			seen[k] = true
+			merged = append(merged, f)
+		}
+	}
+	sortFindings(merged)
+	return merged
+}
+
+// diffFindings returns the findings in the new list that are not in the
+// old, and those in the old that are not in the new, in order of location.
+func diffFindings(old, new []jsonFinding) (introduced, fixed []jsonFinding) {
+	keys := func(list []jsonFinding) map[baselineKey]bool {
+		m := make(map[baselineKey]bool)
+		for _, f := range list {
+			m[baselineKey{f.Kind, f.File, f.Word}] = true
+		}
+		return m
+	}
+	oldKeys, newKeys := keys(old), keys(new)
+	introduced, fixed = []jsonFinding{}, []jsonFinding{}
+	for _, f := range new {
+		if !oldKeys[baselineKey{f.Kind, f.File, f.Word}] {
+			introduced = append(introduced, f)
+		}
+	}
+	for _, f := range old {
+		if !newKeys[baselineKey{f.Kind, f.File, f.Word}] {
+			fixed = append(fixed, f)
+		}
+	}
+	sortFindings(introduced)
+	sortFindings(fixed)
+	return introduced, fixed
+}
+
+// sortFindings sorts the findings by file, line, column, kind, and word.
+func sortFindings(list []jsonFinding) {
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		switch {
+		case a.File != b.File:
+			return a.File < b.File
+		case a.Line != b.Line:
+			return a.Line < b.Line
+		case a.Col != b.Col:
+			return a.Col < b.Col
+		case a.Kind != b.Kind:
+			return a.Kind < b.Kind
+		}
+		return a.Word < b.Word
+	})
+}
+
+// findingString returns the finding as typo prints it in text.
+func findingString(f jsonFinding) string {
+	s := fmt.Sprintf("%s:%d:%d %s %s", f.File, f.Line, f.Col, f.Kind, f.Word)
+	if f.Kind == "typo" {
+		s = fmt.Sprintf("%s:%d:%d %s [%d] %s", f.File, f.Line, f.Col, f.Kind, f.Score, f.Word)
+	}
+	if f.Note != "" {
+		s += " (" + f.Note + ")"
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"regexp"
+	"strings"
+)
+
+var rstMode = flag.Bool("rst", false, "skip reStructuredText markup: directives, literal blocks, roles, and substitutions")
+
+func init() {
+	addFilter(filter{name: "rst", on: func() bool { return *rstMode }, markup: rstFilter})
+}
+
+// rstProse lists the directives whose bodies are prose to be checked.
+// The bodies of all others, such as code-block and math, are skipped.
+var rstProse = map[string]bool{
+	"admonition":     true,
+	"attention":      true,
+	"caution":        true,
+	"danger":         true,
+	"deprecated":     true,
+	"epigraph":       true,
+	"error":          true,
+	"hint":           true,
+	"important":      true,
+	"note":           true,
+	"rubric":         true,
+	"seealso":        true,
+	"sidebar":        true,
+	"tip":            true,
+	"topic":          true,
+	"versionadded":   true,
+	"versionchanged": true,
+	"warning":        true,
+}
+
+var (
+	// .. name:: arguments
+	rstDirective = regexp.MustCompile(`^\s*\.\.\s+([\w:+-]+)::`)
+	// .. |name| directive:: arguments
+	rstSubstDef = regexp.MustCompile(`^\s*\.\.\s+\|[^|]+\|`)
+	// :field name: at the start of a line.
+	rstField = regexp.MustCompile(`^\s*:[^:\s][^:]*:(\s|$)`)
+
+	rstInline = []*regexp.Regexp{
+		regexp.MustCompile("``[^`]+``"),                         // ``literal``
+		regexp.MustCompile(":[\\w:+-]+:`[^`]*`"),                // :role:`text`
+		regexp.MustCompile("`[^`]+`:[\\w:+-]+:"),                // `text`:role:
+		regexp.MustCompile("`([^`<]*[^`<\\s])\\s*<[^>`]*>`__?"), // `link text <url>`_
+		regexp.MustCompile("`[^`]+`"),                           // `interpreted text`
+		regexp.MustCompile(`\|[^|\s][^|]*\|_{0,2}`),             // |substitution|
+		regexp.MustCompile(`\[[^\]\s]+\]_`),                     // [#footnote]_
+	}
+)
+
+// rstFilter returns a filter that blanks out reStructuredText markup:
+// directives, comments, and substitution definitions, with the bodies of
+// those that are not prose; literal blocks introduced by "::"; field names;
+// and inline literals, roles, targets, and substitution references.
+func rstFilter() lineFilter {
+	skip := -1 // Skip lines indented more than this; -1 means don't skip.
+	return func(line string) string {
+		ind := indent(line)
+		if skip >= 0 {
+			if ind < 0 || ind > skip {
+				return blank(line, 0, len(line))
+			}
+			skip = -1
+		}
+		switch m := rstDirective.FindStringSubmatch(line); {
+		case m != nil:
+			if !rstProse[m[1]] {
+				skip = ind
+			}
+			return blank(line, 0, len(line))
+		case rstSubstDef.MatchString(line),
+			strings.HasPrefix(strings.TrimSpace(line), ".. ") || strings.TrimSpace(line) == "..":
+			// A substitution definition, comment, or hyperlink target, with its indented body.
+			skip = ind
+			return blank(line, 0, len(line))
+		}
+		if m := rstField.FindStringIndex(line); m != nil {
+			line = blank(line, 0, m[1])
+		}
+		for _, re := range rstInline {
+			line = blankMatches(line, re)
+		}
+		if strings.HasSuffix(strings.TrimRight(line, " \t"), "::") {
+			// A literal block follows.
+			skip = ind
+		}
+		return line
+	}
+}
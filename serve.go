@@ -0,0 +1,101 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// With -serve, typo runs an HTTP server. A POST to /check with text as
+// its body returns the findings in the text as JSON:
+//
+//	{"findings": [{"kind": "typo", "line": 3, "col": 7, "word": "teh", "score": 62}]}
+//
+// A Content-Type of text/html or text/markdown selects the HTML or Markdown
+// filter. As with -lsp, each text is analyzed on its own, with the model
+// and corpus, if any, providing the rest of the statistics.
+
+var serveAddr = flag.String("serve", "", "serve HTTP requests to check text on the `address`, such as :8080")
+
+// maxBody bounds the size of the text in a request.
+const maxBody = 16 << 20
+
+// A jsonFinding is a finding as presented in JSON.
+type jsonFinding struct {
+	Kind     string `json:"kind"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line"`
+	Col      int    `json:"col"`
+	Word     string `json:"word"`
+	Score    int    `json:"score"`
+	Severity string `json:"severity,omitempty"`
+	Note     string `json:"note,omitempty"`
+	Count    int    `json:"count,omitempty"`
+}
+
+// serveMu serializes the requests, as the analysis uses global state.
+var serveMu sync.Mutex
+
+// serveHTTP runs the HTTP server.
+func serveHTTP(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/check", handleCheck)
+	return http.ListenAndServe(addr, mux)
+}
+
+func handleCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "POST the text to check", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	kind, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	findings := checkText(string(body), kind)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"findings": findings})
+}
+
+// checkText analyzes the text, filtered according to its media type, and
+// returns its findings in order of position.
+func checkText(text, mediaType string) []jsonFinding {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	defer func(html, markdown bool) {
+		*filterHTML, *markdownMode = html, markdown
+	}(*filterHTML, *markdownMode)
+	switch mediaType {
+	case "text/html":
+		*filterHTML = true
+	case "text/markdown", "text/x-markdown":
+		*markdownMode = true
+	}
+	reset()
+	add("<request>", strings.NewReader(text))
+	findings := []jsonFinding{}
+	if !*noRepeats {
+		for _, w := range repeats() {
+			findings = append(findings, jsonFinding{Kind: "repeat", Line: w.lineNum, Col: w.col, Word: w.text(), Severity: severity(w, "repeat")})
+		}
+	}
+	stats()
+	for _, w := range words {
+		if w.score < *threshold || !w.candidate() {
+			continue
+		}
+		findings = append(findings, jsonFinding{Kind: "typo", Line: w.lineNum, Col: w.col, Word: w.text(), Score: int(w.score), Severity: severity(w, "typo")})
+	}
+	return findings
+}
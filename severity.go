@@ -0,0 +1,48 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var severityBands = flag.String("severity", "20,10", "the `scores` at or above which an unlikely word is an error and a warning; below, it is info")
+
+// errorScore and warningScore are the boundaries set by -severity.
+var errorScore, warningScore float64 = 20, 10
+
+// parseSeverity parses -severity.
+func parseSeverity() {
+	e, w, ok := strings.Cut(*severityBands, ",")
+	var err1, err2 error
+	errorScore, err1 = strconv.ParseFloat(strings.TrimSpace(e), 64)
+	warningScore, err2 = strconv.ParseFloat(strings.TrimSpace(w), 64)
+	if !ok || err1 != nil || err2 != nil || warningScore > errorScore {
+		fmt.Fprintf(os.Stderr, "typo: bad -severity %q; want error and warning scores, such as 20,10\n", *severityBands)
+		os.Exit(2)
+	}
+}
+
+// severity returns the severity of a finding of the kind: for an unlikely
+// word, error, warning, or info according to its score; for a repeat,
+// warning; and for the rest, info.
+func severity(w *Word, kind string) string {
+	switch kind {
+	case "typo":
+		switch {
+		case w.score >= errorScore:
+			return "error"
+		case w.score >= warningScore:
+			return "warning"
+		}
+	case "repeat":
+		return "warning"
+	}
+	return "info"
+}
@@ -0,0 +1,134 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Words are separated by white space and by the characters of -break-chars,
+// and trimmed of the punctuation at their ends except for the characters of
+// -word-chars. With -strip-possessive, a trailing 's is trimmed too.
+
+var (
+	splitIdents     = flag.Bool("split-identifiers", false, "split camelCase and snake_case identifiers into words")
+	splitHyphens    = flag.Bool("split-hyphens", false, "split hyphenated words such as well-known into words")
+	wordChars       = flag.String("word-chars", "", "punctuation `characters` to keep at the ends of words, such as '")
+	breakChars      = flag.String("break-chars", "", "`characters` that separate words as white space does, such as _/")
+	stripPossessive = flag.Bool("strip-possessive", false, "trim a trailing 's from words, so typo's is checked as typo")
+)
+
+func init() {
+	addFilter(filter{name: "split-identifiers", on: func() bool { return *splitIdents }})
+	addFilter(filter{name: "split-hyphens", on: func() bool { return *splitHyphens }})
+}
+
+// isBreak reports whether c separates words.
+func isBreak(c rune) bool {
+	return unicode.IsSpace(c) || *breakChars != "" && strings.ContainsRune(*breakChars, c)
+}
+
+// isTrimmed reports whether c is trimmed from the ends of words.
+func isTrimmed(c rune) bool {
+	return unicode.IsPunct(c) && !strings.ContainsRune(*wordChars, c)
+}
+
+// trimPossessive returns the word without a trailing 's, if -strip-possessive is set.
+func trimPossessive(s string) string {
+	if !*stripPossessive {
+		return s
+	}
+	for _, suffix := range []string{"'s", "'S", "\u2019s", "\u2019S"} {
+		if t, ok := strings.CutSuffix(s, suffix); ok && t != "" {
+			return strings.TrimRightFunc(t, isTrimmed)
+		}
+	}
+	return s
+}
+
+// A part is a piece of a token, with its byte offset in the token.
+type part struct {
+	text string
+	off  int
+}
+
+// splitParts splits each of the parts with the function, adjusting the offsets.
+func splitParts(parts []part, split func(string) []part) []part {
+	var out []part
+	for _, p := range parts {
+		for _, q := range split(p.text) {
+			out = append(out, part{q.text, p.off + q.off})
+		}
+	}
+	return out
+}
+
+// isHyphen reports whether c is a hyphen.
+func isHyphen(c rune) bool {
+	return c == '-' || c == '\u2010' || c == '\u2011'
+}
+
+// splitHyphenated splits a word at its hyphens, trimming punctuation from
+// the pieces: "well-known" becomes "well" and "known".
+func splitHyphenated(s string) []part {
+	var parts []part
+	start := 0
+	for i := 0; i <= len(s); {
+		c, wid := utf8.DecodeRuneInString(s[i:])
+		if i < len(s) && !isHyphen(c) {
+			i += wid
+			continue
+		}
+		text := strings.TrimLeftFunc(s[start:i], isTrimmed)
+		off := start + (i - start - len(text))
+		text = strings.TrimRightFunc(text, isTrimmed)
+		if text != "" {
+			parts = append(parts, part{text, off})
+		}
+		if i == len(s) {
+			break
+		}
+		i += wid
+		start = i
+	}
+	return parts
+}
+
+// splitIdentifier splits an identifier into its component words, breaking at
+// underscores and at changes of case: "max_retry_count" becomes "max",
+// "retry", "count", and "HTTPServerError" becomes "HTTP", "Server", "Error".
+// An ordinary word is returned whole.
+func splitIdentifier(s string) []part {
+	var parts []part
+	start := 0
+	emit := func(end int) {
+		if end > start {
+			parts = append(parts, part{s[start:end], start})
+		}
+	}
+	var prev rune
+	for i, c := range s {
+		switch {
+		case c == '_':
+			emit(i)
+			start = i + 1
+		case unicode.IsUpper(c) && unicode.IsLower(prev):
+			// retryCount: break before C.
+			emit(i)
+			start = i
+		case unicode.IsLower(c) && unicode.IsUpper(prev) && i-utf8.RuneLen(prev) > start:
+			// HTTPServer: break before S.
+			j := i - utf8.RuneLen(prev)
+			emit(j)
+			start = j
+		}
+		prev = c
+	}
+	emit(len(s))
+	return parts
+}
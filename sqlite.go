@@ -0,0 +1,118 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// With -format=sqlite, the findings are added to the SQLite database named
+// by -o, which is created if need be, by the sqlite3 command. Each run adds
+// a row to the runs table and rows, bearing its id, to the others:
+//
+//	runs(id, started, seconds, args, threshold, files, lines, words)
+//	files(run, path, size, modified, words, findings)
+//	findings(run, kind, file, line, col, word, score, severity, note)
+//
+// The files table describes the files with words in them.
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY,
+	started TEXT,
+	seconds REAL,
+	args TEXT,
+	threshold REAL,
+	files INTEGER,
+	lines INTEGER,
+	words INTEGER
+);
+CREATE TABLE IF NOT EXISTS files (
+	run INTEGER REFERENCES runs(id),
+	path TEXT,
+	size INTEGER,
+	modified TEXT,
+	words INTEGER,
+	findings INTEGER
+);
+CREATE TABLE IF NOT EXISTS findings (
+	run INTEGER REFERENCES runs(id),
+	kind TEXT,
+	file TEXT,
+	line INTEGER,
+	col INTEGER,
+	word TEXT,
+	score INTEGER,
+	severity TEXT,
+	note TEXT
+);
+`
+
+// writeSQLite adds the run and its findings to the -o database.
+func writeSQLite(reps, list []*Word) {
+	var b strings.Builder
+	b.WriteString(sqliteSchema)
+	b.WriteString("BEGIN;\n")
+	fmt.Fprintf(&b, "INSERT INTO runs (started, seconds, args, threshold, files, lines, words) VALUES (%s, %g, %s, %g, %d, %d, %d);\n",
+		sqlQuote(startTime.UTC().Format(time.RFC3339)), time.Since(startTime).Seconds(), sqlQuote(strings.Join(os.Args[1:], " ")),
+		*threshold, totals.files, totals.lines, len(words))
+	const run = "(SELECT max(id) FROM runs)"
+	findings := make(map[string]int)
+	row := func(w *Word, kind, note string) {
+		findings[w.file]++
+		fmt.Fprintf(&b, "INSERT INTO findings VALUES (%s, %s, %s, %d, %d, %s, %d, %s, %s);\n",
+			run, sqlQuote(kind), sqlQuote(w.file), w.lineNum, w.col, sqlQuote(w.text()), int(w.score), sqlQuote(severity(w, kind)), sqlQuote(note))
+	}
+	for _, w := range reps {
+		row(w, "repeat", "")
+	}
+	for _, w := range confused {
+		row(w, "confusable", confusableNote(w))
+	}
+	for _, m := range mixed {
+		row(m.british, "variant", m.note())
+		row(m.american, "variant", m.note())
+	}
+	for _, w := range list {
+		row(w, "typo", typoNote(w))
+	}
+	count := make(map[string]int)
+	for _, w := range words {
+		count[w.file]++
+	}
+	var files []string
+	for file := range count {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		size, modified := "NULL", "NULL"
+		if info, err := os.Stat(file); err == nil {
+			size = fmt.Sprint(info.Size())
+			modified = sqlQuote(info.ModTime().UTC().Format(time.RFC3339))
+		}
+		fmt.Fprintf(&b, "INSERT INTO files VALUES (%s, %s, %s, %s, %d, %d);\n", run, sqlQuote(file), size, modified, count[file], findings[file])
+	}
+	b.WriteString("COMMIT;\n")
+	cmd := exec.Command("sqlite3", "-bail", *outFile)
+	cmd.Stdin = strings.NewReader(b.String())
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: sqlite3 %s: %v: %s\n", *outFile, err, bytes.TrimSpace(stderr.Bytes()))
+		os.Exit(2)
+	}
+}
+
+// sqlQuote returns s as an SQL string literal.
+func sqlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,99 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"robpike.io/cmd/typo/trigram"
+)
+
+var dumpStatsFile = flag.String("dump-stats", "", "write the digram and trigram counts of the input to `file`, as gob if its name ends in .gob and as text otherwise")
+
+// pooledTable returns the statistics of all the input. With -per-file, the
+// table holds only the model's counts, so it builds a new one.
+func pooledTable() *trigram.Table {
+	if !*perFile || corpus != nil {
+		return table
+	}
+	t := newTable()
+	for _, w := range words {
+		if !w.ignore {
+			t.Add(form(w.text()))
+		}
+	}
+	return t
+}
+
+// dumpStats writes the statistics of the input to the file named by -dump-stats.
+func dumpStats() {
+	f, err := os.Create(*dumpStatsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		os.Exit(2)
+	}
+	t := pooledTable()
+	if strings.HasSuffix(*dumpStatsFile, ".gob") {
+		err = t.Write(f)
+	} else {
+		err = t.WriteText(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: writing %s: %s\n", *dumpStatsFile, err)
+		os.Exit(2)
+	}
+}
+
+// statsCmd implements the stats subcommand:
+//
+//	typo stats file ngram...
+//
+// It prints the counts in the file, which is a model or the output of
+// -dump-stats in either form, for each digram or trigram. For a trigram xyz
+// it prints n(xy), n(yz), and n(xyz). The rune '.' marks the beginning or
+// end of a word.
+func statsCmd(args []string) {
+	if len(args) < 2 {
+		fmt.Fprintf(os.Stderr, "usage: typo stats file ngram...\n")
+		os.Exit(2)
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		os.Exit(2)
+	}
+	t, err := trigram.Read(bytes.NewReader(data))
+	if err != nil {
+		t, err = trigram.ReadText(bytes.NewReader(data))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: reading %s: %s\n", args[0], err)
+		os.Exit(2)
+	}
+	status := 0
+	for _, arg := range args[1:] {
+		r := []rune(arg)
+		switch len(r) {
+		case 2:
+			fmt.Printf("%s\tn(%s)=%d\n", arg, arg, t.DiCount(trigram.Digram{r[0], r[1]}))
+		case 3:
+			fmt.Printf("%s\tn(%s)=%d\tn(%s)=%d\tn(%s)=%d\n", arg,
+				string(r[:2]), t.DiCount(trigram.Digram{r[0], r[1]}),
+				string(r[1:]), t.DiCount(trigram.Digram{r[1], r[2]}),
+				arg, t.TriCount(trigram.Trigram{r[0], r[1], r[2]}))
+		default:
+			fmt.Fprintf(os.Stderr, "typo: %q is not a digram or trigram\n", arg)
+			status = 2
+		}
+	}
+	os.Exit(status)
+}
@@ -0,0 +1,73 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// With -stream, typo reports each unlikely word, and each repeat, as soon
+// as it reads it, rather than once it has read all the input. As the input
+// alone is too little to judge the first words by, the statistics must come
+// from a model, the word-list model, or a corpus; except with a corpus,
+// each word's counts are added to them as it arrives. Each unlikely word is
+// reported once, where it first appears, and -n does not apply.
+
+var streamMode = flag.Bool("stream", false, "report words as they are read, scoring them against -model, -corpus, or -wordlist")
+
+// stream reads the files, or standard input if there are none, reporting
+// the findings as it goes.
+func stream(files []string) {
+	if model == nil && corpus == nil && wordlist == nil {
+		fmt.Fprintf(os.Stderr, "typo: -stream needs statistics from -model, -corpus, -wordlist, or -wordlist-only\n")
+		os.Exit(2)
+	}
+	if *format != "text" || findingTemplate != nil || *listFiles || *print0 {
+		fmt.Fprintf(os.Stderr, "typo: -stream prints only text\n")
+		os.Exit(2)
+	}
+	var r recent
+	reported := make(map[string]bool)
+	lowMemWord = func(text, line, file string, lineNum, byteNum int) {
+		lower := lowerCase(text)
+		if r.repeatsAt(lower, wordLoc{file, lineNum, byteNum}) && !*noRepeats {
+			w := newOwnWord(text, file, lineNum, byteNum, column(line, byteNum))
+			if !suppressed(w) {
+				printFinding(w, repeatSuffix(w))
+			}
+			w.release()
+		}
+		if ignored(text) {
+			return
+		}
+		if corpus == nil {
+			table.Add(form(text))
+		}
+		if reported[text] || isKnown(lower) {
+			return
+		}
+		score := table.Score(form(text))
+		if score < *threshold && !(*ocrMode && ocrReading(text) != "") {
+			return
+		}
+		w := newOwnWord(text, file, lineNum, byteNum, column(line, byteNum))
+		defer w.release()
+		if suppressed(w) {
+			return
+		}
+		w.score = score
+		reported[w.text()] = true
+		printFinding(w, diagnosis(w)+lineNote(w))
+	}
+	if len(files) == 0 {
+		add("<stdin>", os.Stdin)
+	}
+	for _, f := range files {
+		add(f, nil)
+	}
+	lowMemWord = nil
+}
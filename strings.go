@@ -0,0 +1,185 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+)
+
+var stringsMode = flag.Bool("strings", false, "check only the user-visible strings of Android strings.xml and Apple .strings files")
+
+func init() {
+	addFilter(filter{name: "strings", on: func() bool { return *stringsMode }, selects: stringsFilter})
+}
+
+// stringsFilter returns a filter that blanks out all but the user-visible
+// strings of the resource file, or nil if the file is not one. Android
+// resources are recognized by the name strings.xml, Apple's by the suffix
+// .strings. Format verbs such as %1$s and %@ are blanked out too.
+func stringsFilter(file string) lineFilter {
+	switch {
+	case strings.EqualFold(filepath.Base(file), "strings.xml"):
+		return androidStringsFilter()
+	case strings.EqualFold(filepath.Ext(file), ".strings"):
+		return appleStringsFilter()
+	}
+	return nil
+}
+
+// androidStringsFilter returns the filter for Android strings.xml files,
+// which keeps the text of the string elements and of the items of plurals
+// and string-array elements.
+func androidStringsFilter() lineFilter {
+	inComment := false // Inside <!-- -->.
+	inTag := false     // Inside < >.
+	inCDATA := false   // Inside <![CDATA[ ]]>.
+	inText := false    // Inside a <string> or <item>.
+	tag := ""          // The text of the tag being read.
+	return func(line string) string {
+		v := newValueLine(line)
+		for i := 0; i < len(line); {
+			switch {
+			case inComment:
+				j := strings.Index(line[i:], "-->")
+				if j < 0 {
+					v.blank(i, len(line))
+					return v.String()
+				}
+				v.blank(i, i+j+3)
+				i += j + 3
+				inComment = false
+			case inTag:
+				j := strings.IndexByte(line[i:], '>')
+				if j < 0 {
+					tag += line[i:] + " "
+					v.blank(i, len(line))
+					return v.String()
+				}
+				tag += line[i : i+j]
+				v.blank(i, i+j+1)
+				i += j + 1
+				inTag = false
+				inText = androidTextTag(tag, inText)
+			case inCDATA:
+				j := strings.Index(line[i:], "]]>")
+				end := len(line)
+				if j >= 0 {
+					end = i + j
+				}
+				if !inText {
+					v.blank(i, end)
+				}
+				// CDATA usually holds HTML; blank out its tags.
+				for k := i; k < end; k++ {
+					if line[k] == '<' {
+						n := strings.IndexByte(line[k:end], '>')
+						if n < 0 {
+							n = end - k - 1
+						}
+						v.blank(k, k+n+1)
+						k += n
+					}
+				}
+				i = end
+				if j >= 0 {
+					v.blank(i, i+3)
+					i += 3
+					inCDATA = false
+				}
+			case strings.HasPrefix(line[i:], "<!--"):
+				v.blank(i, i+4)
+				i += 4
+				inComment = true
+			case strings.HasPrefix(line[i:], "<![CDATA["):
+				v.blank(i, i+9)
+				i += 9
+				inCDATA = true
+			case line[i] == '<':
+				v.blank(i, i+1)
+				i++
+				inTag, tag = true, ""
+			case !inText:
+				v.blank(i, i+1)
+				i++
+			case line[i] == '\\':
+				// An escape such as \n or \'.
+				v.blank(i, i+2)
+				i += 2
+			case line[i] == '&':
+				j := strings.IndexByte(line[i:], ';')
+				if j < 0 || j > 10 {
+					j = 0
+				}
+				v.blank(i, i+j+1)
+				i += j + 1
+			default:
+				i++
+			}
+		}
+		return blankMatches(v.String(), printfVerb)
+	}
+}
+
+// androidTextTag reports whether the text after the tag, given without
+// its angle brackets, is a string to check, given whether the text before
+// it was. Strings marked translatable="false" are not checked.
+func androidTextTag(tag string, inText bool) bool {
+	tag = strings.TrimSpace(tag)
+	name, attrs, _ := strings.Cut(tag, " ")
+	switch name {
+	case "string", "item":
+		return !strings.HasSuffix(tag, "/") && !strings.Contains(attrs, `translatable="false"`)
+	case "/string", "/item":
+		return false
+	}
+	// Markup such as <b> inside a string leaves it a string.
+	return inText
+}
+
+// appleStringsFilter returns the filter for Apple .strings files, which
+// keeps the values of the "key" = "value"; entries.
+func appleStringsFilter() lineFilter {
+	inComment := false // Inside /* */.
+	value := false     // Whether the next string is a value.
+	return func(line string) string {
+		v := newValueLine(line)
+		for i := 0; i < len(line); {
+			switch {
+			case inComment:
+				j := strings.Index(line[i:], "*/")
+				if j < 0 {
+					v.blank(i, len(line))
+					return v.String()
+				}
+				v.blank(i, i+j+2)
+				i += j + 2
+				inComment = false
+			case strings.HasPrefix(line[i:], "/*"):
+				v.blank(i, i+2)
+				i += 2
+				inComment = true
+			case strings.HasPrefix(line[i:], "//"):
+				v.blank(i, len(line))
+				i = len(line)
+			case line[i] == '"':
+				i, _ = v.quoted(i, `"`, value)
+			case line[i] == '=':
+				value = true
+				v.blank(i, i+1)
+				i++
+			case line[i] == ';':
+				value = false
+				v.blank(i, i+1)
+				i++
+			default:
+				v.blank(i, i+1)
+				i++
+			}
+		}
+		return blankMatches(v.String(), printfVerb)
+	}
+}
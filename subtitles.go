@@ -0,0 +1,66 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var subtitlesMode = flag.Bool("subtitles", false, "check only the caption text of SubRip .srt and WebVTT .vtt files")
+
+func init() {
+	addFilter(filter{name: "subtitles", on: func() bool { return *subtitlesMode }, selects: subtitlesFilter})
+}
+
+// captionMarkup matches the markup within caption text: tags such as <i>,
+// <v Speaker>, and <00:01:02.000>, SubRip's {\an8}, and entities.
+var captionMarkup = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}|&[a-zA-Z]+;`)
+
+// subtitlesFilter returns a filter that blanks out all but the caption text
+// of the subtitle file, or nil if the file is not one. It records the index
+// and start time of the cue of each line of text as line notes.
+func subtitlesFilter(file string) lineFilter {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".srt", ".vtt":
+	default:
+		return nil
+	}
+	notes := make(map[int][]textNote)
+	lineNotes[file] = notes
+	lineNum := 0
+	cues := 0      // The number of cues so far.
+	inCue := false // Whether the lines are the text of a cue.
+	id := ""       // The identifier of the cue, the line before its timing.
+	note := ""     // The note for the lines of the cue.
+	return func(line string) string {
+		lineNum++
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			// Cues, and WebVTT's header, notes, and style blocks, end at blank lines.
+			inCue, id = false, ""
+			return line
+		case strings.Contains(line, "-->"):
+			cues++
+			if id == "" {
+				id = fmt.Sprint(cues)
+			}
+			start, _, _ := strings.Cut(trimmed, "-->")
+			note = "cue " + id + " at " + strings.TrimSpace(start)
+			inCue = true
+			return blank(line, 0, len(line))
+		case !inCue:
+			// A cue's identifier or a line that is not a caption's.
+			id = trimmed
+			return blank(line, 0, len(line))
+		}
+		notes[lineNum] = []textNote{{1, note}}
+		return blankMatches(line, captionMarkup)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxEditDistance bounds how far a suggestion may be from the word.
+const maxEditDistance = 2
+
+// suggest returns up to n known words close to the word, closest first,
+// in the case of the word. Closeness is measured by edit distance, counting
+// a transposition of adjacent letters as a single edit. Of words equally
+// close, those whose differing letters are on keys next to the word's own,
+// as by a slip of the finger, come first. Words that sound like the word
+// are suggested even if they are somewhat further away.
+func suggest(word string, n int) []string {
+	lower := strings.ToLower(word)
+	r := []rune(lower)
+	type candidate struct {
+		word  string
+		dist  int
+		slips int
+	}
+	var cands []candidate
+	for k := range known {
+		// Cheap rejection by length.
+		if d := utf8.RuneCountInString(k) - len(r); d > maxEditDistance || d < -maxEditDistance {
+			continue
+		}
+		if d := editDistance(r, []rune(k)); d <= maxEditDistance && k != lower {
+			cands = append(cands, candidate{k, d, slips(r, []rune(k))})
+		}
+	}
+	// Words that sound like the word may be further away.
+	for _, k := range soundsLike(lower) {
+		if d := editDistance(r, []rune(k)); d > maxEditDistance && d <= maxPhoneticDistance(len(r)) {
+			cands = append(cands, candidate{k, d, 0})
+		}
+	}
+	sort.Slice(cands, func(i, j int) bool {
+		if cands[i].dist != cands[j].dist {
+			return cands[i].dist < cands[j].dist
+		}
+		if cands[i].slips != cands[j].slips {
+			return cands[i].slips > cands[j].slips
+		}
+		return cands[i].word < cands[j].word
+	})
+	var out []string
+	ocr := ""
+	if *ocrMode {
+		ocr = ocrReading(word)
+	}
+	if ocr != "" {
+		out = append(out, ocr)
+	}
+	for i := 0; i < len(cands) && len(out) < n; i++ {
+		if s := matchCase(word, cands[i].word); s != ocr {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// editDistance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions, and transpositions of
+// adjacent runes needed to turn one into the other.
+func editDistance(a, b []rune) int {
+	// d[i][j] is the distance between a[:i] and b[:j]; we keep three rows.
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d = min(d, prev2[j-2]+1)
+			}
+			cur[j] = d
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// matchCase returns s, a lower-case word, in the case of model: all upper
+// case, capitalized, or as is.
+func matchCase(model, s string) string {
+	first, _ := utf8.DecodeRuneInString(model)
+	switch {
+	case model == strings.ToUpper(model) && utf8.RuneCountInString(model) > 1:
+		return strings.ToUpper(s)
+	case unicode.IsUpper(first):
+		r, wid := utf8.DecodeRuneInString(s)
+		return string(unicode.ToUpper(r)) + s[wid:]
+	}
+	return s
+}
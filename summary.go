@@ -0,0 +1,72 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"text/tabwriter"
+)
+
+var summary = flag.Bool("summary", false, "print only counts of words and findings for each file, and totals")
+
+// A fileSummary holds the counts for one file.
+type fileSummary struct {
+	name     string
+	words    int // Words scanned.
+	typos    int // Distinct unlikely words.
+	repeats  int
+	maxScore int
+}
+
+// printSummary prints, for each file, the number of words scanned, of
+// distinct unlikely words, and of repeats, and the highest score of any
+// unknown word, followed by the totals.
+func printSummary(reps []*Word) {
+	var files []*fileSummary
+	byName := make(map[string]*fileSummary)
+	get := func(name string) *fileSummary {
+		s := byName[name]
+		if s == nil {
+			s = &fileSummary{name: name}
+			byName[name] = s
+			files = append(files, s)
+		}
+		return s
+	}
+	seen := make(map[string]map[string]bool) // Unlikely words seen in each file.
+	for _, w := range words {
+		s := get(w.file)
+		s.words++
+		if !w.candidate() {
+			continue
+		}
+		s.maxScore = max(s.maxScore, int(w.score))
+		if w.score < *threshold {
+			continue
+		}
+		if seen[w.file] == nil {
+			seen[w.file] = make(map[string]bool)
+		}
+		if !seen[w.file][w.text()] {
+			seen[w.file][w.text()] = true
+			s.typos++
+		}
+	}
+	for _, w := range reps {
+		get(w.file).repeats++
+	}
+	total := fileSummary{name: "total"}
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "file\twords\ttypos\trepeats\tmax\n")
+	for _, s := range append(files, &total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", s.name, s.words, s.typos, s.repeats, s.maxScore)
+		total.words += s.words
+		total.typos += s.typos
+		total.repeats += s.repeats
+		total.maxScore = max(total.maxScore, s.maxScore)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,92 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Markers in the input suppress findings, so a document may hold
+// misspellings on purpose. The marker typo:ignore followed by words, such
+// as typo:ignore recieve teh, suppresses those words throughout the file;
+// typo:ignore-line suppresses everything on its line. Markers usually sit
+// in comments, as in <!-- typo:ignore recieve -->, and are not themselves
+// checked. The suppressed words still count in the statistics.
+//
+// The markers typo:off and typo:on exclude the lines between them, such as
+// a changelog or a quotation, from both the report and the statistics.
+// The lines holding the markers themselves are checked as usual.
+
+// suppressMarker matches a marker. The list of words ends at anything not
+// word-like, such as the --> that closes an HTML comment.
+var suppressMarker = regexp.MustCompile(`typo:(?:off|on)\b|typo:ignore-line|typo:ignore((?:[ \t,]+[\pL\pN'’_.-]*[\pL\pN])*)`)
+
+var (
+	suppressedWords = make(map[string]map[string]bool) // By file, the folded words.
+	suppressedLines = make(map[string]map[int]bool)    // By file, the line numbers.
+)
+
+// suppressFilter returns a filter that blanks out the markers in the file,
+// recording what they suppress in place of what was recorded before.
+func suppressFilter(file string) lineFilter {
+	delete(suppressedWords, file)
+	delete(suppressedLines, file)
+	lineNum := 0
+	off := false // Whether we are between typo:off and typo:on.
+	return func(line string) string {
+		lineNum++
+		if off && !strings.Contains(line, "typo:on") {
+			return blank(line, 0, len(line))
+		}
+		if !strings.Contains(line, "typo:") {
+			return line
+		}
+		for _, m := range suppressMarker.FindAllStringSubmatchIndex(line, -1) {
+			switch marker := line[m[0]:m[1]]; {
+			case marker == "typo:off":
+				off = true
+			case marker == "typo:on":
+				off = false
+			case m[2] < 0:
+				if suppressedLines[file] == nil {
+					suppressedLines[file] = make(map[int]bool)
+				}
+				suppressedLines[file][lineNum] = true
+			default:
+				if suppressedWords[file] == nil {
+					suppressedWords[file] = make(map[string]bool)
+				}
+				for _, w := range strings.FieldsFunc(line[m[2]:m[3]], isListSeparator) {
+					suppressedWords[file][fold(w)] = true
+				}
+			}
+			line = blank(line, m[0], m[1])
+		}
+		return line
+	}
+}
+
+// isListSeparator reports whether c separates the words of a marker.
+func isListSeparator(c rune) bool {
+	return c == ' ' || c == '\t' || c == ','
+}
+
+// recordSuppressions records the suppressions made by the markers in the
+// file, whose words were not read through its filter.
+func recordSuppressions(file string, data []byte) {
+	if !strings.Contains(string(data), "typo:") {
+		return
+	}
+	f := suppressFilter(file)
+	for _, line := range splitLines(string(data)) {
+		f(line)
+	}
+}
+
+// suppressed reports whether a marker suppresses the finding of the word.
+func suppressed(w *Word) bool {
+	return suppressedLines[w.file][w.lineNum] || suppressedWords[w.file][w.lower()]
+}
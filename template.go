@@ -0,0 +1,89 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// With -format-template, each finding is printed by executing a text/template
+// on a value with the fields File, Line, Col, Score, Word, Kind ("typo",
+// "repeat", "confusable", or "variant"), and Severity ("error", "warning",
+// or "info"), and the method Suggestions, which returns the known words
+// closest to Word. The function join is strings.Join. A newline follows each finding.
+// For example:
+//
+//	typo -format-template '{{.File}}({{.Line}}): {{.Word}} -> {{join .Suggestions ", "}}'
+
+var formatTemplate = flag.String("format-template", "", "print each finding using the text/template `template`; see the doc comment")
+
+// findingTemplate is the parsed -format-template.
+var findingTemplate *template.Template
+
+// A templateFinding is the value given to -format-template.
+type templateFinding struct {
+	File     string
+	Line     int
+	Col      int
+	Score    int
+	Word     string
+	Kind     string
+	Severity string
+}
+
+// Suggestions returns the known words closest to the word.
+func (f *templateFinding) Suggestions() []string {
+	return suggest(f.Word, 5)
+}
+
+// parseTemplate parses -format-template, if set.
+func parseTemplate() {
+	if *formatTemplate == "" {
+		return
+	}
+	t, err := template.New("finding").Funcs(template.FuncMap{"join": strings.Join}).Parse(*formatTemplate)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: -format-template: %s\n", err)
+		os.Exit(2)
+	}
+	findingTemplate = t
+}
+
+// writeTemplate prints the findings using -format-template.
+func writeTemplate(reps, list []*Word) {
+	print := func(w *Word, kind string) {
+		f := &templateFinding{
+			File:     w.file,
+			Line:     w.lineNum,
+			Col:      w.col,
+			Score:    int(w.score),
+			Word:     w.text(),
+			Kind:     kind,
+			Severity: severity(w, kind),
+		}
+		if err := findingTemplate.Execute(out, f); err != nil {
+			fmt.Fprintf(os.Stderr, "typo: -format-template: %s\n", err)
+			os.Exit(2)
+		}
+		fmt.Fprintln(out)
+	}
+	for _, w := range reps {
+		print(w, "repeat")
+	}
+	for _, w := range confused {
+		print(w, "confusable")
+	}
+	for _, m := range mixed {
+		print(m.british, "variant")
+		print(m.american, "variant")
+	}
+	for _, w := range list {
+		print(w, "typo")
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+var showTotals = flag.Bool("stats", false, "print totals for the run on standard error: files, lines, words, distinct and known words, findings, and time")
+
+// totals counts the files and lines read.
+var totals struct {
+	files, lines int
+}
+
+// startTime is when the run began.
+var startTime = time.Now()
+
+// countLines returns the number of lines in the data.
+func countLines(data []byte) int {
+	lines := splitLinesAfter(data)
+	if len(lines[len(lines)-1]) == 0 {
+		return len(lines) - 1
+	}
+	return len(lines)
+}
+
+// printTotals prints the totals for the run, whose findings are the repeats
+// and the unlikely words.
+func printTotals(reps, list []*Word) {
+	distinct := make(map[string]bool)
+	known := 0
+	for _, w := range words {
+		distinct[w.lower()] = true
+		if isKnown(w.lower()) {
+			known++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "files: %d\n", totals.files)
+	fmt.Fprintf(os.Stderr, "lines: %d\n", totals.lines)
+	fmt.Fprintf(os.Stderr, "words: %d\n", len(words))
+	fmt.Fprintf(os.Stderr, "distinct words: %d\n", len(distinct))
+	fmt.Fprintf(os.Stderr, "known words: %d\n", known)
+	fmt.Fprintf(os.Stderr, "repeats: %d\n", len(reps))
+	fmt.Fprintf(os.Stderr, "unlikely words: %d\n", len(list))
+	fmt.Fprintf(os.Stderr, "time: %s\n", time.Since(startTime).Round(time.Millisecond))
+}
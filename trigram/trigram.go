@@ -0,0 +1,529 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package trigram implements the digram and trigram statistics used by typo
+// to compute an index of peculiarity for each word of a document, following
+// Bell Labs CSTR 18 by Robert Morris and Lorinda L. Cherry.
+//
+// The counts are gathered from every word of the document, including the one
+// being scored; the score subtracts one from each count to remove the effect
+// of the word itself. A word with a score greater than 10 contains trigrams
+// that are not representative of the rest of the document.
+package trigram // import "robpike.io/cmd/typo/trigram"
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Digram is a pair of adjacent runes in a word. The rune '.' marks the
+// beginning or end of the word.
+type Digram [2]rune
+
+// A Trigram is a triple of adjacent runes in a word. The rune '.' marks the
+// beginning or end of the word.
+type Trigram [3]rune
+
+// Table holds the digram and trigram counts for a body of text.
+//
+// Most text is in lower-case ASCII letters, so the counts of the digrams
+// and trigrams made only of those and the marker '.' are kept in arrays,
+// which are much faster to index than maps. The maps hold the rest.
+type Table struct {
+	Di  map[Digram]int  // Counts of the digrams not in di.
+	Tri map[Trigram]int // Counts of the trigrams not in tri.
+
+	di  []int // Dense digram counts, indexed by denseDi.
+	tri []int // Dense trigram counts, indexed by denseTri.
+
+	reference bool        // See SetReference.
+	combine   Combination // See SetCombination.
+	zero      Zero        // See SetZero.
+}
+
+// A Combination is a way of combining the indices of the trigrams of a word
+// into its score. The paper evaluated all three.
+type Combination int
+
+const (
+	RMS  Combination = iota // The square root of the mean of the squares; the default.
+	Mean                    // The mean.
+	Max                     // The maximum.
+)
+
+// dense is the number of runes with dense indexes: '.' and a to z.
+const dense = 27
+
+// denseIndex returns the index of r in the dense arrays, or -1 if it has none.
+func denseIndex(r rune) int {
+	switch {
+	case r == '.':
+		return 0
+	case 'a' <= r && r <= 'z':
+		return int(r-'a') + 1
+	}
+	return -1
+}
+
+// denseDi returns the index of the digram in the dense array, or -1 if it has none.
+func denseDi(d Digram) int {
+	i, j := denseIndex(d[0]), denseIndex(d[1])
+	if i < 0 || j < 0 {
+		return -1
+	}
+	return i*dense + j
+}
+
+// denseTri returns the index of the trigram in the dense array, or -1 if it has none.
+func denseTri(t Trigram) int {
+	i, j, k := denseIndex(t[0]), denseIndex(t[1]), denseIndex(t[2])
+	if i < 0 || j < 0 || k < 0 {
+		return -1
+	}
+	return (i*dense+j)*dense + k
+}
+
+// New returns an empty Table.
+func New() *Table {
+	return &Table{
+		Di:  make(map[Digram]int),
+		Tri: make(map[Trigram]int),
+		di:  make([]int, dense*dense),
+		tri: make([]int, dense*dense*dense),
+	}
+}
+
+// DiCount returns the count of the digram.
+func (t *Table) DiCount(d Digram) int {
+	if i := denseDi(d); i >= 0 {
+		return t.di[i]
+	}
+	return t.Di[d]
+}
+
+// TriCount returns the count of the trigram.
+func (t *Table) TriCount(tri Trigram) int {
+	if i := denseTri(tri); i >= 0 {
+		return t.tri[i]
+	}
+	return t.Tri[tri]
+}
+
+func (t *Table) incDigram(d Digram, n int) {
+	if i := denseDi(d); i >= 0 {
+		t.di[i] += n
+		return
+	}
+	t.Di[d] += n
+}
+
+func (t *Table) incTrigram(tri Trigram, n int) {
+	if i := denseTri(tri); i >= 0 {
+		t.tri[i] += n
+		return
+	}
+	t.Tri[tri] += n
+}
+
+// Add adds the digrams and trigrams of the word to the table.
+func (t *Table) Add(word string) {
+	t.addDigrams(word)
+	Scan(word, t.incTrigrams)
+}
+
+// Scan calls fn for each trigram of the word, including the initial and
+// terminal ones, so a word has as many trigrams as it has runes.
+func Scan(word string, fn func(t Trigram)) {
+	// For "once", we have ".on", "onc", "nce", "ce."
+	// Do the first one by hand to prime the pump.
+	rune, wid := utf8.DecodeRuneInString(word)
+	t := Trigram{'.', '.', rune}
+	for _, r := range word[wid:] {
+		t[0] = t[1]
+		t[1] = t[2]
+		t[2] = r
+		fn(t)
+	}
+	// At this point, we have "nce"; make "ce.".
+	// If there was only one letter, "a", we have "..a" and this tail will give us ".a.", which is what we want.
+	// Final marker
+	t[0] = t[1]
+	t[1] = t[2]
+	t[2] = '.'
+	fn(t)
+}
+
+func (t *Table) addDigrams(word string) {
+	d := Digram{'.', '.'}
+	// For "once", we have ".o", "on", "nc", "ce", "e."
+	for _, r := range word {
+		d[0] = d[1]
+		d[1] = r
+		t.incDigram(d, 1)
+	}
+	// Final marker
+	d[0] = d[1]
+	d[1] = '.'
+	t.incDigram(d, 1)
+}
+
+func (t *Table) incTrigrams(tri Trigram) {
+	t.incTrigram(tri, 1)
+}
+
+// SetReference sets whether the table is a reference: one built from other
+// text, to which the words being scored did not contribute. The counts of a
+// reference table are used as they are, rather than reduced by one to remove
+// the effect of the word being scored.
+func (t *Table) SetReference(ref bool) {
+	t.reference = ref
+}
+
+// A Zero is a way of handling a digram or trigram count of zero in TriScore.
+type Zero int
+
+const (
+	Skip    Zero = iota // The trigram's index is zero; the default.
+	Paper               // The log of zero is taken to be -10, as in the paper.
+	Laplace             // Additive smoothing, so no count is zero.
+)
+
+// alphabet is the number of runes that may follow a digram in a trigram,
+// for additive smoothing: '.' and a to z.
+const alphabet = dense
+
+// SetZero sets how TriScore handles counts of zero.
+func (t *Table) SetZero(z Zero) {
+	t.zero = z
+}
+
+// SetCombination sets how Score combines the indices of the trigrams.
+func (t *Table) SetCombination(c Combination) {
+	t.combine = c
+}
+
+// TriScore returns the index i(T) for the trigram, the evidence against the
+// hypothesis that it came from the same source as the rest of the table.
+// Counts of zero are handled as set by SetZero. With Paper, the log of a
+// trigram count of zero is -10, so an unseen trigram has an index of at
+// least 10; a digram count of zero, which means the trigram is unseen too,
+// is taken as one, so the absence of the digram cannot cancel that
+// evidence. With Laplace, one is added to the trigram count and the size
+// of the alphabet to each digram count, as in add-one smoothing of the
+// probability of the trigram given its digrams, so an unseen trigram of
+// unseen digrams has an index of log(alphabet), about 3.3, rather than 0.
+func (t *Table) TriScore(tri Trigram) float64 {
+	i, _ := t.index(tri)
+	return i
+}
+
+// index returns the index of the trigram, as TriScore does, and whether
+// its counts were all non-zero.
+func (t *Table) index(tri Trigram) (float64, bool) {
+	self := 1
+	if t.reference {
+		self = 0
+	}
+	nxy := float64(t.DiCount(Digram{tri[0], tri[1]}) - self)
+	nyz := float64(t.DiCount(Digram{tri[1], tri[2]}) - self)
+	nxyz := float64(t.TriCount(tri) - self)
+	seen := nxy > 0 && nyz > 0 && nxyz > 0
+	switch t.zero {
+	case Paper:
+		return 0.5*(paperLog(max(nxy, 1))+paperLog(max(nyz, 1))) - paperLog(nxyz), seen
+	case Laplace:
+		nxy += alphabet
+		nyz += alphabet
+		nxyz++
+	default:
+		// The paper says to use -10 for log(0), but its square is 100, so that can't be right.
+		if !seen {
+			return 0, false
+		}
+	}
+	logNxy := math.Log(nxy)
+	logNyz := math.Log(nyz)
+	logNxyz := math.Log(nxyz)
+	return 0.5*(logNxy+logNyz) - logNxyz, seen
+}
+
+// paperLog returns the log of n, taking the log of zero to be -10.
+func paperLog(n float64) float64 {
+	if n <= 0 {
+		return -10
+	}
+	return math.Log(n)
+}
+
+// Score returns the index of peculiarity of the word, which is higher the
+// less the word's trigrams resemble the rest of the table. The indices of
+// its trigrams are combined as set by SetCombination: by default, the
+// square root of the mean of their squares.
+//
+// By default, a trigram with a zero count has index zero, and the score is
+// 10 divided by the combination of the indices' magnitudes, so 10 is the
+// boundary of the unusual and a word whose trigrams all score zero gets
+// 100. The maximum instead measures the evidence of the most peculiar
+// trigram: a trigram with a zero count is the strongest, and gives the
+// word a score of 100; otherwise the largest index is put on the same
+// scale, as described at scale. With the paper's handling of zero
+// counts, or additive smoothing, the indices themselves measure the
+// evidence against the word, as in the paper, and their combination is
+// put on that scale too, so a score of 10 is the boundary of the unusual
+// whatever the method. Their index of a trigram is higher the commoner
+// its digrams, so with the maximum a word whose digrams are unseen too
+// may score low.
+func (t *Table) Score(word string) float64 {
+	c, unseen := t.Combined(word)
+	if t.zero == Skip && t.combine == Max && unseen {
+		return 100
+	}
+	if lo, hi, ok := t.Scale(); ok {
+		return scaled([2]float64{lo, hi}, c)
+	}
+	s := 10 / c
+	if math.IsInf(s, 0) || math.IsNaN(s) {
+		s = 100.
+	}
+	return s
+}
+
+// Combined returns the combination of the indices of the word's trigrams
+// from which Score computes the word's score, and whether any of the
+// trigrams has a count of zero. By default, other than for the maximum,
+// it is the combination of the indices' magnitudes.
+func (t *Table) Combined(word string) (float64, bool) {
+	var indices []float64
+	unseen := false
+	Scan(word, func(tri Trigram) {
+		i, seen := t.index(tri)
+		indices = append(indices, i)
+		unseen = unseen || !seen
+	})
+	if t.zero == Skip && t.combine != Max {
+		for k, i := range indices {
+			indices[k] = math.Abs(i)
+		}
+	}
+	return combine(t.combine, indices), unseen
+}
+
+// Scale returns the combined indices to which Score gives scores of 10 and
+// 20, as described at scale. It returns false if the score is instead 10
+// divided by the combined index, as by default.
+func (t *Table) Scale() (lo, hi float64, ok bool) {
+	if t.zero == Skip && t.combine != Max {
+		return 0, 0, false
+	}
+	s := scale[t.zero][t.combine]
+	return s[0], s[1], true
+}
+
+// scale holds, for each combination of indices whose score grows with the
+// combined index, the combined indices that score 10 and 20. Between and
+// beyond them the score is linear in the index, and at least zero. They
+// were chosen so that, in English text such as the Go specification and
+// the GPL, about as many of the unknown words score 10 and 20 or more as
+// with the default handling of zero counts and RMS; so the threshold and
+// the severity bands mean the same whatever the method. For the maximum,
+// which is the index of a trigram with non-zero counts, the bounds are
+// those of the paper's index of a single trigram. With additive
+// smoothing, the index of an unseen trigram of unseen digrams is about
+// 3.3, so the bound for RMS is set a little below that, and a word made
+// of them scores above 10.
+var scale = [...][Max + 1][2]float64{
+	Skip:    {Max: {13.3, 14.4}},
+	Paper:   {RMS: {8.2, 9.8}, Mean: {6.3, 8.5}, Max: {13.3, 14.4}},
+	Laplace: {RMS: {3.2, 3.6}, Mean: {3.1, 3.5}, Max: {4.5, 4.8}},
+}
+
+// scaled returns the score of the combined index c on the scale, whose
+// elements are the indices that score 10 and 20.
+func scaled(scale [2]float64, c float64) float64 {
+	return max(10+10*(c-scale[0])/(scale[1]-scale[0]), 0)
+}
+
+// combine returns the combination of the indices.
+func combine(c Combination, indices []float64) float64 {
+	if len(indices) == 0 {
+		return 0
+	}
+	switch c {
+	case Mean:
+		sum := 0.0
+		for _, i := range indices {
+			sum += i
+		}
+		return sum / float64(len(indices))
+	case Max:
+		m := indices[0]
+		for _, i := range indices[1:] {
+			m = max(m, i)
+		}
+		return m
+	}
+	sum := 0.0
+	for _, i := range indices {
+		sum += i * i
+	}
+	return math.Sqrt(sum / float64(len(indices)))
+}
+
+// Merge adds the counts of u to t.
+func (t *Table) Merge(u *Table) {
+	for d, n := range u.Di {
+		t.Di[d] += n
+	}
+	for tri, n := range u.Tri {
+		t.Tri[tri] += n
+	}
+	for i, n := range u.di {
+		t.di[i] += n
+	}
+	for i, n := range u.tri {
+		t.tri[i] += n
+	}
+}
+
+// MergeScaled adds the counts of u, multiplied by w and rounded, to t.
+func (t *Table) MergeScaled(u *Table, w float64) {
+	scale := func(n int) int {
+		return int(math.Round(float64(n) * w))
+	}
+	for d, n := range u.Di {
+		t.Di[d] += scale(n)
+	}
+	for tri, n := range u.Tri {
+		t.Tri[tri] += scale(n)
+	}
+	for i, n := range u.di {
+		t.di[i] += scale(n)
+	}
+	for i, n := range u.tri {
+		t.tri[i] += scale(n)
+	}
+}
+
+// Digrams calls fn for each digram with a non-zero count.
+func (t *Table) Digrams(fn func(d Digram, n int)) {
+	for d, n := range t.Di {
+		if n != 0 {
+			fn(d, n)
+		}
+	}
+	for i, n := range t.di {
+		if n != 0 {
+			fn(Digram{denseRune(i / dense), denseRune(i % dense)}, n)
+		}
+	}
+}
+
+// Trigrams calls fn for each trigram with a non-zero count.
+func (t *Table) Trigrams(fn func(tri Trigram, n int)) {
+	for tri, n := range t.Tri {
+		if n != 0 {
+			fn(tri, n)
+		}
+	}
+	for i, n := range t.tri {
+		if n != 0 {
+			fn(Trigram{denseRune(i / (dense * dense)), denseRune(i / dense % dense), denseRune(i % dense)}, n)
+		}
+	}
+}
+
+// Write writes the table to w in a form that Read can decode.
+// All the counts are written to the maps, so the form does not depend
+// on the arrays.
+func (t *Table) Write(w io.Writer) error {
+	u := &Table{
+		Di:  make(map[Digram]int, len(t.Di)),
+		Tri: make(map[Trigram]int, len(t.Tri)),
+	}
+	t.Digrams(func(d Digram, n int) { u.Di[d] = n })
+	t.Trigrams(func(tri Trigram, n int) { u.Tri[tri] = n })
+	return gob.NewEncoder(w).Encode(u)
+}
+
+// WriteText writes the table to w as text that ReadText can decode: a line
+// for each digram and then each trigram, holding its runes and its count,
+// separated by a space. The lines of each kind are sorted.
+func (t *Table) WriteText(w io.Writer) error {
+	var lines []string
+	t.Digrams(func(d Digram, n int) {
+		lines = append(lines, fmt.Sprintf("%s %d", string(d[:]), n))
+	})
+	sort.Strings(lines)
+	ndi := len(lines)
+	t.Trigrams(func(tri Trigram, n int) {
+		lines = append(lines, fmt.Sprintf("%s %d", string(tri[:]), n))
+	})
+	sort.Strings(lines[ndi:])
+	b := bufio.NewWriter(w)
+	for _, line := range lines {
+		fmt.Fprintln(b, line)
+	}
+	return b.Flush()
+}
+
+// ReadText reads a table written by WriteText.
+func ReadText(r io.Reader) (*Table, error) {
+	t := New()
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: no count", lineNum)
+		}
+		n, err := strconv.Atoi(line[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad count: %v", lineNum, err)
+		}
+		runes := []rune(line[:i])
+		switch len(runes) {
+		case 2:
+			t.incDigram(Digram{runes[0], runes[1]}, n)
+		case 3:
+			t.incTrigram(Trigram{runes[0], runes[1], runes[2]}, n)
+		default:
+			return nil, fmt.Errorf("line %d: %q is not a digram or trigram", lineNum, line[:i])
+		}
+	}
+	return t, scanner.Err()
+}
+
+// denseRune is the inverse of denseIndex.
+func denseRune(i int) rune {
+	if i == 0 {
+		return '.'
+	}
+	return 'a' + rune(i-1)
+}
+
+// Read reads a table written by Write.
+func Read(r io.Reader) (*Table, error) {
+	var u Table
+	if err := gob.NewDecoder(r).Decode(&u); err != nil {
+		return nil, err
+	}
+	t := New()
+	for d, n := range u.Di {
+		t.incDigram(d, n)
+	}
+	for tri, n := range u.Tri {
+		t.incTrigram(tri, n)
+	}
+	return t, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trigram
+
+import (
+	"testing"
+
+	"robpike.io/cmd/typo/dict"
+)
+
+// rank returns the rank, from 1, of the score of the word among the scores
+// of the words.
+func rank(t *Table, words []string, word string) int {
+	s := t.Score(word)
+	r := 1
+	for _, w := range words {
+		if t.Score(w) > s {
+			r++
+		}
+	}
+	return r
+}
+
+// TestPlantedTypos checks that, with the paper's handling of zero counts
+// and with additive smoothing, typos planted in English words rank among
+// the most peculiar of them: the unseen trigrams raise their scores.
+func TestPlantedTypos(t *testing.T) {
+	words := dict.Words(dict.Bundled["en"])
+	planted := []string{"suspcious", "xqzvy"}
+	words = append(words, planted...)
+	tests := []struct {
+		zero    Zero
+		combine Combination
+		top     int // Percentage of the words within whose ranks the typos must fall.
+	}{
+		{Paper, RMS, 5},
+		{Paper, Mean, 5},
+		{Paper, Max, 10},
+		{Laplace, RMS, 5},
+		{Laplace, Mean, 5},
+		{Laplace, Max, 15},
+	}
+	for _, test := range tests {
+		table := New()
+		table.SetZero(test.zero)
+		table.SetCombination(test.combine)
+		for _, w := range words {
+			table.Add(w)
+		}
+		for _, w := range planted {
+			if r := rank(table, words, w); r > len(words)*test.top/100 {
+				t.Errorf("zero %d, combination %d: %q ranks %d of %d; want within the top %d%%", test.zero, test.combine, w, r, len(words), test.top)
+			}
+		}
+	}
+}
+
+// TestThreshold checks that, with the paper's handling of zero counts and
+// with additive smoothing, nonsense words planted in English words score
+// at least 10, the default threshold of typo.
+func TestThreshold(t *testing.T) {
+	words := dict.Words(dict.Bundled["en"])
+	planted := []string{"xqzvy", "zxqwv"}
+	words = append(words, planted...)
+	for _, zero := range []Zero{Paper, Laplace} {
+		for _, combine := range []Combination{RMS, Mean} {
+			table := New()
+			table.SetZero(zero)
+			table.SetCombination(combine)
+			for _, w := range words {
+				table.Add(w)
+			}
+			for _, w := range planted {
+				if s := table.Score(w); s < 10 {
+					t.Errorf("zero %d, combination %d: %q scores %.2f; want at least 10", zero, combine, w, s)
+				}
+			}
+		}
+	}
+}
+
+// TestMaxTransposed checks that, with the maximum, a word with two letters
+// transposed scores above the word spelled correctly, even if an index of
+// the correct word is near 0.
+func TestMaxTransposed(t *testing.T) {
+	words := dict.Words(dict.Bundled["en"])
+	pairs := []struct{ word, typo string }{
+		{"the", "teh"},
+		{"and", "adn"},
+		{"that", "taht"},
+		{"with", "wtih"},
+		{"about", "abuot"},
+		{"through", "thorugh"},
+		{"example", "exmaple"},
+		{"different", "diffrent"},
+		{"probably", "porbably"},
+		{"something", "somehting"}, // One index of "something" is near 0.
+	}
+	for _, p := range pairs {
+		words = append(words, p.typo)
+	}
+	for _, zero := range []Zero{Skip, Paper, Laplace} {
+		table := New()
+		table.SetZero(zero)
+		table.SetCombination(Max)
+		for _, w := range words {
+			table.Add(w)
+		}
+		for _, p := range pairs {
+			if s, st := table.Score(p.word), table.Score(p.typo); st <= s {
+				t.Errorf("zero %d: %q scores %.2f, not above %q at %.2f", zero, p.typo, st, p.word, s)
+			}
+		}
+	}
+}
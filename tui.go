@@ -0,0 +1,274 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// With -tui, typo shows the unlikely words in a full-screen list on the
+// terminal, with the selected word in context beneath, and each word may be
+// marked: to be fixed, with one of the suggested corrections; to be ignored;
+// or to be added to the personal list of known words (see learn.go). On
+// quitting with q, the decisions are carried out: the fixes are made in
+// the files, as by -fix; the ignored words are recorded in the -baseline
+// file, or typo-baseline.json, for use with -baseline; and the words to be
+// learned are learned. The terminal is driven by stty and ANSI escape
+// sequences.
+
+var tuiMode = flag.Bool("tui", false, "triage the unlikely words interactively in a full-screen terminal interface")
+
+// The marks a word may be given.
+const (
+	markNone = iota
+	markFix
+	markIgnore
+	markLearn
+)
+
+var markSymbols = []string{" ", "F", "I", "A"}
+
+// A tuiItem is a word in the list and what to do with it.
+type tuiItem struct {
+	w    *Word
+	sugg []string // Suggestions, computed when first needed.
+	mark int
+	repl string // With markFix, the correction.
+}
+
+func (it *tuiItem) suggestions() []string {
+	if it.sugg == nil {
+		it.sugg = suggest(it.w.text(), 9)
+		if it.sugg == nil {
+			it.sugg = []string{}
+		}
+	}
+	return it.sugg
+}
+
+// tuiHelp is the status line.
+const tuiHelp = "j/k move  1-9/f fix  i ignore  a add to dictionary  u unmark  q save and quit  x quit"
+
+// tui runs the interface on the list of words.
+func tui(list []*Word) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: -tui needs a terminal: %s\n", err)
+		os.Exit(2)
+	}
+	defer tty.Close()
+	saved, err := stty(tty, "-g")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: stty: %s\n", err)
+		os.Exit(2)
+	}
+	if _, err := stty(tty, "raw", "-echo"); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: stty: %s\n", err)
+		os.Exit(2)
+	}
+	items := make([]*tuiItem, 0, len(list))
+	for _, w := range list[:min(len(list), *nTypos)] {
+		items = append(items, &tuiItem{w: w})
+	}
+	save := tuiLoop(tty, items)
+	stty(tty, strings.TrimSpace(saved))
+	fmt.Fprint(tty, "\x1b[H\x1b[2J")
+	if save {
+		tuiSave(items)
+	}
+}
+
+// stty runs stty on the terminal with the arguments and returns its output.
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// tuiLoop runs the interface until the user quits, and reports whether
+// to carry out the decisions.
+func tuiLoop(tty *os.File, items []*tuiItem) bool {
+	sel, top := 0, 0
+	buf := make([]byte, 16)
+	for {
+		rows, cols := 24, 80
+		if size, err := stty(tty, "size"); err == nil {
+			var r, c int
+			if fmt.Sscan(size, &r, &c); r > 0 && c > 0 {
+				rows, cols = r, c
+			}
+		}
+		const pane = 9 // Lines below the list: rule, location, context, suggestions, mark, help.
+		height := max(rows-pane, 1)
+		if sel < top {
+			top = sel
+		} else if sel >= top+height {
+			top = sel - height + 1
+		}
+		tuiDraw(tty, items, sel, top, height, cols)
+		n, err := tty.Read(buf)
+		if err != nil {
+			return false
+		}
+		key := string(buf[:n])
+		var it *tuiItem
+		if len(items) > 0 {
+			it = items[sel]
+		}
+		switch key {
+		case "q":
+			return true
+		case "x", "\x03": // x or ^C
+			return false
+		case "j", "\x1b[B", "\x0e": // j, down, ^N
+			sel = min(sel+1, max(len(items)-1, 0))
+		case "k", "\x1b[A", "\x10": // k, up, ^P
+			sel = max(sel-1, 0)
+		case " ", "\x1b[6~": // space, page down
+			sel = min(sel+height, max(len(items)-1, 0))
+		case "b", "\x1b[5~": // b, page up
+			sel = max(sel-height, 0)
+		case "g":
+			sel = 0
+		case "G":
+			sel = max(len(items)-1, 0)
+		}
+		if it == nil {
+			continue
+		}
+		switch key {
+		case "i":
+			it.mark = markIgnore
+		case "a":
+			it.mark = markLearn
+		case "u":
+			it.mark = markNone
+		case "f", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			i := 0
+			if key != "f" {
+				i, _ = strconv.Atoi(key)
+				i--
+			}
+			if sugg := it.suggestions(); i < len(sugg) && it.w.file != "<stdin>" {
+				it.mark = markFix
+				it.repl = sugg[i]
+			}
+		}
+	}
+}
+
+// tuiDraw draws the screen.
+func tuiDraw(tty *os.File, items []*tuiItem, sel, top, height, cols int) {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	line := func(s string) {
+		b.WriteString(clip(s, cols))
+		b.WriteString("\r\n")
+	}
+	for i := top; i < top+height; i++ {
+		if i >= len(items) {
+			line("")
+			continue
+		}
+		it := items[i]
+		s := fmt.Sprintf("%s %s", markSymbols[it.mark], it.w)
+		if it.mark == markFix {
+			s += " -> " + it.repl
+		}
+		if i == sel {
+			b.WriteString(ansiReverse + clip(s, cols) + ansiReset + "\r\n")
+			continue
+		}
+		line(s)
+	}
+	line(strings.Repeat("-", cols))
+	if len(items) == 0 {
+		line("No unlikely words.")
+	} else {
+		it := items[sel]
+		w := it.w
+		line(fmt.Sprintf("%s:%d:%d  %d of %d", w.file, w.lineNum, w.col, sel+1, len(items)))
+		for n := w.lineNum - 1; n <= w.lineNum+1; n++ {
+			text := strings.ReplaceAll(fileLine(w.file, n), "\t", "    ")
+			if n == w.lineNum {
+				text = strings.Replace(text, w.text(), ansiReverse+w.text()+ansiReset, 1)
+				b.WriteString("> " + text + "\r\n")
+				continue
+			}
+			line("  " + text)
+		}
+		var sugg []string
+		for i, s := range it.suggestions() {
+			sugg = append(sugg, fmt.Sprintf("%d) %s", i+1, s))
+		}
+		line(strings.Join(sugg, "  "))
+		switch it.mark {
+		case markFix:
+			line("fix: " + it.repl)
+		case markIgnore:
+			line("ignore")
+		case markLearn:
+			line("add to dictionary")
+		default:
+			line("")
+		}
+	}
+	b.WriteString(clip(tuiHelp, cols))
+	fmt.Fprint(tty, b.String())
+}
+
+// clip truncates s to n runes.
+func clip(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// tuiSave carries out the decisions.
+func tuiSave(items []*tuiItem) {
+	edits := make(map[string][]edit)
+	var ignore []*Word
+	var learned []string
+	for _, it := range items {
+		switch it.mark {
+		case markFix:
+			addEdits(edits, it.w.text(), it.repl)
+		case markIgnore:
+			ignore = append(ignore, it.w)
+		case markLearn:
+			learned = append(learned, it.w.text())
+		}
+	}
+	if len(ignore) > 0 {
+		file := *baselineFile
+		if file == "" {
+			file = "typo-baseline.json"
+		}
+		findings, err := readFindings(file)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			os.Exit(2)
+		}
+		findings = appendTypoFindings(findings, occurrences(ignore))
+		if err := saveBaseline(file, findings); err != nil {
+			fmt.Fprintf(os.Stderr, "typo: writing baseline: %s\n", err)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "typo: recorded %d ignored words in %s; use -baseline %[2]s\n", len(ignore), file)
+	}
+	if err := learn(learned); err != nil {
+		fmt.Fprintf(os.Stderr, "typo: learning: %s\n", err)
+		os.Exit(2)
+	}
+	applyEdits(edits)
+}
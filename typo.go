@@ -16,6 +16,13 @@
 // The -r flag suppresses reporting repeated words.
 // The -n and -t flags control how many "typos" to print.'
 // The -html flag enables simple filtering of HTML from the input.
+// Run typo -help for the other flags; each is described further in the source
+// file that implements it. Defaults for the flags may be set in a configuration
+// file; see config.go. The first argument may name a subcommand: scan, the
+// default, train, serve, fix, dict, stats, or report; see command.go.
+//
+// Typo exits with status 1 if -fail-over is set and at least that many words
+// are found, and with status 2, which takes precedence, if some input could not be read.
 //
 // See the comments in the source for a description of the algorithm, extracted
 // from Bell Labs CSTR 18 by Robert Morris and Lorinda L. Cherry.
@@ -26,8 +33,6 @@ package main // import "robpike.io/cmd/typo"
 
 import (
 	"bufio"
-	"bytes"
-	_ "embed"
 	"flag"
 	"fmt"
 	"io"
@@ -35,18 +40,36 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"text/tabwriter"
 	"unicode"
 	"unicode/utf8"
-)
 
-//go:embed w2006.txt
-var wordsFile []byte
+	"golang.org/x/text/unicode/norm"
+
+	"robpike.io/cmd/typo/trigram"
+)
 
 var (
 	nTypos     = flag.Int("n", 50, "maximum number of words to print")
+	nPerFile   = flag.Int("n-per-file", 0, "maximum number of words to print from each file; 0 means no limit")
 	noRepeats  = flag.Bool("r", false, "don't show repeated words words")
-	threshold  = flag.Int("t", 10, "cutoff threshold; smaller means more words")
+	threshold  = flag.Float64("t", 10, "cutoff threshold; smaller means more words")
+	topPercent = flag.Float64("top-percent", 0, "report the most unlikely `N` percent of distinct unknown words, whatever their scores")
 	filterHTML = flag.Bool("html", false, "filter HTML tags from input")
+	failOver   = flag.Int("fail-over", 0, "exit with status 1 if this many words score above threshold; 0 means never")
+	colMode    = flag.String("col", "byte", "report columns as `unit`: byte, rune, or visual")
+	tabWidth   = flag.Int("tabwidth", 8, "tab width for -col=visual")
+	lspMode    = flag.Bool("lsp", false, "run as a language server on standard input and output")
+	fileList   = flag.String("files", "", "read the names of files to scan from `file`; - means standard input")
+	nulList    = flag.Bool("0", false, "names in the -files list are separated by NUL bytes, not newlines")
+	columns    = flag.Bool("columns", false, "print the words and scores in three columns, without locations")
+	allLocs    = flag.Bool("all-locations", false, "report every occurrence of each unlikely word, not just the first")
+	showCounts = flag.Bool("counts", false, "show how many times each unlikely word occurs, as in [14] x3 word")
+	groupFiles = flag.Bool("group-by-file", false, "report the findings file by file, in order of location, under a header naming each file")
+	sortOrder  = flag.String("sort", "score", "order the report by `key`: score, location, or word")
+	zeroCounts = flag.String("zero", "skip", "handle zero n-gram counts by `method`: skip the trigram, use log(0) = -10 as in the paper, or laplace smoothing")
+	combine    = flag.String("combine", "rms", "combine the indices of a word's trigrams into its score by `method`: rms, mean, or max")
+	perFile    = flag.Bool("per-file", false, "compute statistics for each file separately rather than for all files together")
 )
 
 func init() {
@@ -58,39 +81,201 @@ func init() {
 }
 
 func main() {
-	flag.Parse()
-	for _, w := range read("<wordsFile>", bytes.NewReader(wordsFile), bufio.ScanWords) {
-		known[w] = true
+	args := command(os.Args[1:])
+	configure()
+	setupCommitMsg()
+	openOutput()
+	setColor()
+	switch *colMode {
+	case "byte", "rune", "visual":
+	default:
+		fmt.Fprintf(os.Stderr, "typo: unknown column unit %q\n", *colMode)
+		os.Exit(2)
+	}
+	if *groupFiles {
+		*sortOrder = "location"
+	}
+	switch *sortOrder {
+	case "score", "location", "word":
+	default:
+		fmt.Fprintf(os.Stderr, "typo: unknown sort order %q\n", *sortOrder)
+		os.Exit(2)
+	}
+	parseSeverity()
+	setEncoding()
+	setKeys()
+	checkPo()
+	setCSVCols()
+	setJSONPath()
+	checkRepeatsOnly()
+	table.SetCombination(combination())
+	table.SetZero(zeroHandling())
+	logFilters()
+	loadDictionaries()
+	loadStopWords()
+	if *learnMode && !*fixMode {
+		learnLists(args)
+		return
+	}
+	loadWordlist()
+	loadModel()
+	loadCorpus()
+	if *lspMode {
+		if err := serveLSP(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "typo: lsp: %s\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+	if *serveAddr != "" {
+		if err := serveHTTP(*serveAddr); err != nil {
+			fmt.Fprintf(os.Stderr, "typo: serve: %s\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+	files := walk(args)
+	if *commitMsg != "" {
+		files = append(files, *commitMsg)
+	}
+	if *fileList != "" {
+		files = append(files, readFileList(*fileList)...)
+	}
+	if *diffMode || *gitMode {
+		readDiff()
+		if len(files) == 0 {
+			files = changedFiles
+		}
+	}
+	checkLowMem(files)
+	if *streamMode {
+		stream(files)
+		closeOutput()
+		exitIfFailed()
+		return
 	}
-	if len(flag.Args()) == 0 {
+	if *staged {
+		addStaged()
+	} else if len(files) == 0 && *fileList == "" && *commitMsg == "" && changed == nil {
 		add("<stdin>", os.Stdin)
 	}
-	for _, f := range flag.Args() {
-		add(f, nil)
+	var reps []*Word
+	switch {
+	case *repeatsOnly:
+		reps = scanRepeats(files)
+	case *lowMem:
+		reps = scanLowMem(files)
+	default:
+		for _, f := range files {
+			add(f, nil)
+		}
+		if !*noRepeats {
+			for _, word := range repeats() {
+				if inDiff(word) && !suppressed(word) {
+					// Copy, as the scoring to come is no concern of a repeat.
+					w := *word
+					reps = append(reps, &w)
+				}
+			}
+		}
+		if *confusables {
+			confused = findConfusables()
+		}
+		if *consistency {
+			mixed = findMixtures()
+		}
+		stats()
+		if changed != nil {
+			// Only words on changed lines are candidates for reporting.
+			out := words[:0]
+			for _, word := range words {
+				if inDiff(word) {
+					out = append(out, word)
+				}
+			}
+			words = out
+		}
+	}
+	if *dumpStatsFile != "" {
+		dumpStats()
+	}
+	if *freqMode {
+		printFreq()
+		closeOutput()
+		exitIfFailed()
+		return
+	}
+	if *explainWords != "" {
+		explain()
+		closeOutput()
+		exitIfFailed()
+		return
 	}
-	repeats()
-	stats()
-	spell()
+	list := typos()
+	if *writeBaselineFile != "" {
+		writeBaseline(reps, list)
+		exitIfFailed()
+		return
+	}
+	reps, list = applyBaseline(reps, list)
+	switch {
+	case *summary:
+		printSummary(reps)
+	case *fixMode:
+		report(reps, nil)
+		fix(list)
+	case *tuiMode:
+		tui(list)
+	default:
+		report(reps, list)
+	}
+	closeOutput()
+	if *showTotals {
+		printTotals(reps, list)
+	}
+	found := len(list)
+	if *repeatsOnly {
+		found = len(reps)
+	}
+	os.Exit(exitStatus(found))
 }
 
 type Word struct {
-	text    string  // The original.
-	lower   *string // The word in lower case; may point to original.
+	textID  int32 // The original, as an index in strs.
+	lowerID int32 // The word in lower case, as an index in strs; may be textID.
 	file    string
 	lineNum int
 	byteNum int
-	score   int
+	col     int // Column as reported; see -col.
+	score   float64
+	ignore  bool // Not counted in the statistics or scored; see ignore.go.
+}
+
+// text returns the word as it appears in the input.
+func (w *Word) text() string {
+	return strs[w.textID]
+}
+
+// lower returns the word in lower case.
+func (w *Word) lower() string {
+	return strs[w.lowerID]
+}
+
+// candidate reports whether the word may be reported as unlikely.
+func (w *Word) candidate() bool {
+	return !w.ignore && !isKnown(w.lower()) && !suppressed(w)
 }
 
 func (w Word) String() string {
 	if w.score == 0 {
-		return fmt.Sprintf("%s:%d:%d %s", w.file, w.lineNum, w.byteNum, w.text)
+		return fmt.Sprintf("%s:%d:%d %s", w.file, w.lineNum, w.col, w.text())
 	} else {
-		return fmt.Sprintf("%s:%d:%d [%d] %s", w.file, w.lineNum, w.byteNum, w.score, w.text)
+		return fmt.Sprintf("%s:%d:%d %s [%d] %s%s", w.file, w.lineNum, w.col, severity(&w, "typo"), int(w.score), countPrefix(&w), w.text())
 	}
 }
 
-// Sort interfaces for []*Word
+// Sort interfaces for []*Word. Each sorts by its key and then by location,
+// file, line, and column, and then by word, so the order is deterministic.
 type ByScore []*Word
 
 func (t ByScore) Len() int {
@@ -100,7 +285,10 @@ func (t ByScore) Len() int {
 func (t ByScore) Less(i, j int) bool {
 	w1 := t[i]
 	w2 := t[j]
-	return w1.score > w2.score // Sort down
+	if w1.score != w2.score {
+		return w1.score > w2.score // Sort down
+	}
+	return before(w1, w2)
 }
 
 func (t ByScore) Swap(i, j int) {
@@ -116,58 +304,204 @@ func (t ByWord) Len() int {
 func (t ByWord) Less(i, j int) bool {
 	w1 := t[i]
 	w2 := t[j]
-	return w1.text < w2.text
+	if w1.text() != w2.text() {
+		return w1.text() < w2.text()
+	}
+	return before(w1, w2)
 }
 
 func (t ByWord) Swap(i, j int) {
 	t[i], t[j] = t[j], t[i]
 }
 
+type ByLocation []*Word
+
+func (t ByLocation) Len() int {
+	return len(t)
+}
+
+func (t ByLocation) Less(i, j int) bool {
+	return before(t[i], t[j])
+}
+
+func (t ByLocation) Swap(i, j int) {
+	t[i], t[j] = t[j], t[i]
+}
+
+// before reports whether w1 comes before w2 in order of location, and then of text.
+func before(w1, w2 *Word) bool {
+	switch {
+	case w1.file != w2.file:
+		return w1.file < w2.file
+	case w1.lineNum != w2.lineNum:
+		return w1.lineNum < w2.lineNum
+	case w1.byteNum != w2.byteNum:
+		return w1.byteNum < w2.byteNum
+	}
+	return w1.text() < w2.text()
+}
+
+// wordsByText returns the occurrences of each word, in order of location.
+func wordsByText() map[string][]*Word {
+	all := make(map[string][]*Word)
+	for _, w := range words {
+		all[w.text()] = append(all[w.text()], w)
+	}
+	return all
+}
+
+// wordCounts holds the number of occurrences of each word, for -counts.
+var wordCounts map[string]int
+
+// count returns the number of occurrences of the word in the input.
+func count(w *Word) int {
+	if wordCounts == nil {
+		wordCounts = make(map[string]int)
+		for _, w := range words {
+			wordCounts[w.text()]++
+		}
+	}
+	return wordCounts[w.text()]
+}
+
+// countPrefix returns the prefix showing the number of occurrences of the
+// word, as in x3, if -counts is set.
+func countPrefix(w *Word) string {
+	if !*showCounts {
+		return ""
+	}
+	return fmt.Sprintf("x%d ", count(w))
+}
+
+// occurrences returns the list with each word followed by its other
+// occurrences, in order of location.
+func occurrences(list []*Word) []*Word {
+	all := wordsByText()
+	var out []*Word
+	for _, w := range list {
+		out = append(out, w)
+		for _, o := range all[w.text()] {
+			if o != w {
+				out = append(out, o)
+			}
+		}
+	}
+	return out
+}
+
+// order sorts the list as directed by -sort and returns it.
+func order(list []*Word) []*Word {
+	switch *sortOrder {
+	case "location":
+		sort.Sort(ByLocation(list))
+	case "word":
+		sort.Sort(ByWord(list))
+	default:
+		sort.Sort(ByScore(list))
+	}
+	return list
+}
+
 var words = make([]*Word, 0, 1000)
 var known = make(map[string]bool) // loaded from knownWordsFiles
 
-func read(file string, r io.Reader, split bufio.SplitFunc) []string {
+// failed records that some input could not be read. Typo carries on with
+// the rest, but exits with status 2.
+var failed bool
+
+// exitStatus returns the status with which to exit having found the
+// number of findings: 2 if some input could not be read, 1 if there are
+// at least -fail-over findings, and 0 otherwise. A failure to read wins,
+// as the findings are then incomplete.
+func exitStatus(found int) int {
+	switch {
+	case failed:
+		return 2
+	case *failOver > 0 && found >= *failOver:
+		return 1
+	}
+	return 0
+}
+
+// exitIfFailed exits with status 2 if some input could not be read.
+func exitIfFailed() {
+	if failed {
+		os.Exit(2)
+	}
+}
+
+// read calls fn for each line of the file, or of r if it is not nil, in turn.
+func read(file string, r io.Reader, fn func(lineNum int, line string)) {
 	if r == nil {
 		f, err := os.Open(file)
+		if os.IsNotExist(err) {
+			// Not fatal; just warn and carry on with the other files.
+			fmt.Fprintf(os.Stderr, "typo: warning: %s\n", err)
+			failed = true
+			return
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stdout, "typo: %s\n", err)
-			os.Exit(2)
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			failed = true
+			return
 		}
 		defer f.Close()
+		if data, ok := mapInput(f); ok {
+			totals.files++
+			readMapped(data, fn)
+			return
+		}
 		r = f
 	}
+	r, err := decode(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: reading %s: %s\n", file, err)
+		failed = true
+		return
+	}
+	totals.files++
 	scanner := bufio.NewScanner(r)
-	scanner.Split(split)
-	words := make([]string, 0, 1000)
-	for scanner.Scan() {
-		words = append(words, scanner.Text())
+	scanner.Buffer(nil, 1<<24)
+	scanner.Split(scanLines)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		totals.lines++
+		fn(lineNum, scanner.Text())
 	}
 	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stdout, "typo: reading %s: %s\n", file, err)
-		os.Exit(2)
+		// Keep the words read so far, and carry on with the other files.
+		fmt.Fprintf(os.Stderr, "typo: reading %s: %s\n", file, err)
+		failed = true
 	}
-	return words
 }
 
+// add adds the words of the file, or of r if it is not nil, to the list.
+// The file is read a line at a time; only the words are kept.
 func add(file string, r io.Reader) {
-	lines := read(file, r, bufio.ScanLines)
-	for lineNum, line := range lines {
+	if r == nil && (addURL(file) || addFiltered(file) || addPDF(file) || addEPUB(file) || addOffice(file) || addArchive(file) || addCached(file)) {
+		return
+	}
+	filter := markupFilter(file)
+	read(file, r, func(lineNum int, line string) {
+		text := line
+		if filter != nil {
+			text = filter(line)
+		}
 		inWord := false
 		wordStart := 1
-		for byteNum, c := range line {
+		for byteNum, c := range text {
 			switch {
-			case inWord && unicode.IsSpace(c):
-				addWord(line[wordStart:byteNum], file, lineNum+1, wordStart+1)
+			case inWord && isBreak(c):
+				addWord(line[wordStart:byteNum], line, file, lineNum, wordStart+1)
 				inWord = false
-			case !inWord && !unicode.IsSpace(c):
+			case !inWord && !isBreak(c):
 				inWord = true
 				wordStart = byteNum
 			}
 		}
 		if inWord {
-			addWord(line[wordStart:], file, lineNum+1, wordStart+1)
+			addWord(line[wordStart:], line, file, lineNum, wordStart+1)
 		}
-	}
+	})
 }
 
 // leadingHTMLLen returns the length of all HTML tags at the start of the text.
@@ -194,15 +528,37 @@ func trailingHTMLLen(text string) int {
 	if n < 0 {
 		return 0
 	}
-	return len(text) - n + trailingHTMLLen(text[:len(text)-n])
+	return len(text) - n + trailingHTMLLen(text[:n])
+}
+
+// column returns the column of the 1-based byteNum within line, in the units selected by -col.
+func column(line string, byteNum int) int {
+	switch *colMode {
+	case "rune":
+		return utf8.RuneCountInString(line[:byteNum-1]) + 1
+	case "visual":
+		col := 0
+		for _, c := range line[:byteNum-1] {
+			if c == '\t' && *tabWidth > 0 {
+				col += *tabWidth - col%*tabWidth
+			} else {
+				col++
+			}
+		}
+		return col + 1
+	}
+	return byteNum
 }
 
-func addWord(text, file string, lineNum, byteNum int) {
+func addWord(text, line, file string, lineNum, byteNum int) {
 	// Note: '<' is not punctuation according to Unicode.
 	n := len(text)
-	text = strings.TrimLeftFunc(text, unicode.IsPunct)
+	text = strings.TrimLeftFunc(text, isTrimmed)
 	byteNum += n - len(text)
-	text = strings.TrimRightFunc(text, unicode.IsPunct)
+	text = trimPossessive(strings.TrimRightFunc(text, isTrimmed))
+	if skipped(text) {
+		return
+	}
 	if *filterHTML {
 		// Easily defeated by spaces and newlines, but gets things like <code><em>foo</em></code>.
 		n := leadingHTMLLen(text)
@@ -214,10 +570,24 @@ func addWord(text, file string, lineNum, byteNum int) {
 			return
 		}
 		n = len(text)
-		text = strings.TrimLeftFunc(text, unicode.IsPunct)
+		text = strings.TrimLeftFunc(text, isTrimmed)
 		byteNum += n - len(text)
-		text = strings.TrimRightFunc(text, unicode.IsPunct)
+		text = trimPossessive(strings.TrimRightFunc(text, isTrimmed))
 	}
+	parts := []part{{text, 0}}
+	if *splitHyphens {
+		parts = splitParts(parts, splitHyphenated)
+	}
+	if *splitIdents {
+		parts = splitParts(parts, splitIdentifier)
+	}
+	for _, p := range parts {
+		appendWord(p.text, line, file, lineNum, byteNum+p.off)
+	}
+}
+
+// appendWord adds the word to the list, provided it has a letter.
+func appendWord(text, line, file string, lineNum, byteNum int) {
 	// There must be a letter.
 	hasLetter := false
 	for _, c := range text {
@@ -229,19 +599,112 @@ func addWord(text, file string, lineNum, byteNum int) {
 	if !hasLetter {
 		return
 	}
-	word := &Word{
-		text:    text,
+	if lowMemWord != nil {
+		lowMemWord(text, line, file, lineNum, byteNum)
+		return
+	}
+	words = append(words, newWord(text, file, lineNum, byteNum, column(line, byteNum)))
+}
+
+// newWord returns the word with the text at the location, its text and
+// lower-case form interned.
+func newWord(text, file string, lineNum, byteNum, col int) *Word {
+	w := &Word{
+		textID:  intern(text),
 		file:    file,
 		lineNum: lineNum,
 		byteNum: byteNum,
+		col:     col,
+		ignore:  ignored(text),
 	}
-	if onlyLower(text) {
-		word.lower = &word.text
-	} else {
-		x := strings.ToLower(text)
-		word.lower = &x
+	w.lowerID = w.textID
+	if lower := lowerCase(text); lower != text {
+		w.lowerID = intern(lower)
+	}
+	return w
+}
+
+// newOwnWord is like newWord but gives the word its own copies of its text
+// and lower-case form, not shared with other words, which release returns
+// for reuse. The passes that keep few of the words they see, such as
+// -low-mem's, use it so the table of strings does not grow with the input.
+func newOwnWord(text, file string, lineNum, byteNum, col int) *Word {
+	w := &Word{
+		textID:  own(text),
+		file:    file,
+		lineNum: lineNum,
+		byteNum: byteNum,
+		col:     col,
+		ignore:  ignored(text),
+	}
+	w.lowerID = w.textID
+	if lower := lowerCase(text); lower != text {
+		w.lowerID = own(lower)
+	}
+	return w
+}
+
+// release returns the strings of a word made by newOwnWord for reuse.
+// The word must not be used afterwards.
+func (w *Word) release() {
+	strs[w.textID] = ""
+	freeIDs = append(freeIDs, w.textID)
+	if w.lowerID != w.textID {
+		strs[w.lowerID] = ""
+		freeIDs = append(freeIDs, w.lowerID)
+	}
+}
+
+// lowerCase returns the word in lower case.
+func lowerCase(text string) string {
+	if isASCII(text) {
+		return strings.ToLower(text)
+	}
+	if onlyLower(text) && norm.NFC.IsNormalString(text) {
+		return text
 	}
-	words = append(words, word)
+	return fold(text)
+}
+
+// strs holds a single copy of each distinct word and lower-case form, and
+// strIDs the index of each in strs. A Word refers to its text by index,
+// so the words do not pin the lines they came from and the many
+// occurrences of a word share its storage. Words in a memory-mapped file
+// are not copied; see mmap.go. Entries made by own are not in strIDs, and
+// once released their indexes are kept in freeIDs for reuse.
+var (
+	strs    []string
+	strIDs  = make(map[string]int32)
+	freeIDs []int32
+)
+
+// intern returns the index of s in strs, adding it if need be.
+func intern(s string) int32 {
+	if id, ok := strIDs[s]; ok {
+		return id
+	}
+	if !fromMapping {
+		s = strings.Clone(s)
+	}
+	id := int32(len(strs))
+	strs = append(strs, s)
+	strIDs[s] = id
+	return id
+}
+
+// own returns the index of a copy of s in strs that is not shared.
+func own(s string) int32 {
+	if !fromMapping {
+		s = strings.Clone(s)
+	}
+	if n := len(freeIDs); n > 0 {
+		id := freeIDs[n-1]
+		freeIDs = freeIDs[:n-1]
+		strs[id] = s
+		return id
+	}
+	strs = append(strs, s)
+	return int32(len(strs) - 1)
 }
 
 func onlyLower(s string) bool {
@@ -253,136 +716,197 @@ func onlyLower(s string) bool {
 	return true
 }
 
-func repeats() {
-	if *noRepeats {
-		return
+// readFileList returns the file names listed in the file, one per line or,
+// with -0, separated by NUL bytes.
+func readFileList(file string) []string {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+			os.Exit(2)
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: reading %s: %s\n", file, err)
+		os.Exit(2)
+	}
+	sep := "\n"
+	if *nulList {
+		sep = "\x00"
+	}
+	var names []string
+	for _, name := range strings.Split(string(data), sep) {
+		if !*nulList {
+			name = strings.TrimSuffix(name, "\r")
+		}
+		if name != "" {
+			names = append(names, name)
+		}
 	}
-	prev := ""
+	return names
+}
+
+// repeats returns the words that repeat the word before them or, with
+// -near-repeats, one a few words back.
+func repeats() []*Word {
+	var reps []*Word
+	var r recent
 	for _, word := range words {
-		w := *word.lower
-		if w == prev {
-			fmt.Printf("%s repeats\n", word)
+		if r.repeats(word) {
+			reps = append(reps, word)
 		}
-		prev = w
 	}
+	return reps
+}
+
+var table = trigram.New()
+
+// zeroHandling returns the handling of zero counts named by -zero. Every
+// method, with every -combine method, scores on the same scale, so the
+// defaults of -t and -severity serve them all.
+func zeroHandling() trigram.Zero {
+	switch *zeroCounts {
+	case "skip":
+		return trigram.Skip
+	case "paper":
+		return trigram.Paper
+	case "laplace":
+		return trigram.Laplace
+	}
+	fmt.Fprintf(os.Stderr, "typo: unknown zero-count method %q\n", *zeroCounts)
+	os.Exit(2)
+	return 0
 }
 
-type digram [2]rune
-type trigram [3]rune
+// combination returns the method of combining trigram indices named by -combine.
+func combination() trigram.Combination {
+	switch *combine {
+	case "rms":
+		return trigram.RMS
+	case "mean":
+		return trigram.Mean
+	case "max":
+		return trigram.Max
+	}
+	fmt.Fprintf(os.Stderr, "typo: unknown combination method %q\n", *combine)
+	os.Exit(2)
+	return 0
+}
 
-var diCounts = make(map[digram]int)
-var triCounts = make(map[trigram]int)
+// reset discards the words and statistics gathered so far.
+func reset() {
+	words = words[:0]
+	strs, strIDs, freeIDs = nil, make(map[string]int32), nil
+	wordCounts = nil
+	table = newTable()
+}
 
+// stats scores the words, using global statistics or, with -per-file,
+// statistics for each file separately.
 func stats() {
-	// Compute global digram and trigram counts.
-	for _, word := range words {
-		addDigrams(word.text)
-		scanTrigrams(word.text, incTrigrams)
+	if !*perFile {
+		statsFor(words, table)
+		return
 	}
-	// Compute the score for the word.
-	for _, word := range words {
-		if known[*word.lower] {
-			continue
+	// The words of a file are contiguous.
+	for start := 0; start < len(words); {
+		end := start + 1
+		for end < len(words) && words[end].file == words[start].file {
+			end++
 		}
-		word.score = int(score(word.text))
-	}
-}
-
-func scanTrigrams(word string, fn func(t trigram)) {
-	// For "once", we have ".on", "onc", "nce", "ce."
-	// Do the first one by hand to prime the pump.
-	rune, wid := utf8.DecodeRuneInString(word)
-	t := trigram{'.', '.', rune}
-	for _, r := range word[wid:] {
-		t[0] = t[1]
-		t[1] = t[2]
-		t[2] = r
-		fn(t)
-	}
-	// At this point, we have "nce"; make "ce.".
-	// If there was only one letter, "a", we have "..a" and this tail will give us ".a.", which is what we want.
-	// Final marker
-	t[0] = t[1]
-	t[1] = t[2]
-	t[2] = '.'
-	fn(t)
-}
-
-func addDigrams(word string) {
-	d := digram{'.', '.'}
-	// For "once", we have ".o", "on", "nc", "ce", "e."
-	for _, r := range word {
-		d[0] = d[1]
-		d[1] = r
-		diCounts[d]++
-	}
-	// Final marker
-	d[0] = d[1]
-	d[1] = '.'
-	diCounts[d]++
-}
-
-func incTrigrams(t trigram) {
-	triCounts[t]++
-}
-
-func triScore(t trigram) float64 {
-	nxy := float64(diCounts[digram{t[0], t[1]}] - 1)
-	nyz := float64(diCounts[digram{t[1], t[2]}] - 1)
-	nxyz := float64(triCounts[t] - 1)
-	// The paper says to use -10 for log(0), but its square is 100, so that can't be right.
-	if nxy == 0 || nyz == 0 || nxyz == 0 {
-		return 0
+		statsFor(words[start:end], newTable())
+		start = end
 	}
-	logNxy := math.Log(nxy)
-	logNyz := math.Log(nyz)
-	logNxyz := math.Log(nxyz)
-	return 0.5*(logNxy+logNyz) - logNxyz
 }
 
-func score(word string) float64 {
-	sumOfSquares := 0.0
-	n := 0
-	fn := func(t trigram) {
-		i := triScore(t)
-		sumOfSquares += i * i
-		n++
+// statsFor adds the words to the table and scores them against it.
+func statsFor(list []*Word, table *trigram.Table) {
+	// Compute digram and trigram counts, unless we are scoring against a corpus.
+	if corpus == nil {
+		for _, word := range list {
+			if !word.ignore {
+				table.Add(form(word.text()))
+			}
+		}
 	}
-	scanTrigrams(word, fn)
-	s := 10 / math.Sqrt(sumOfSquares/float64(n))
-	if math.IsInf(s, 0) {
-		s = 100.
+	// Compute the score for the word.
+	for _, word := range list {
+		if !word.candidate() {
+			continue
+		}
+		word.score = table.Score(form(word.text()))
 	}
-	return s
 }
 
-func spell() {
+// typos returns the distinct unknown words that scored at or above the
+// threshold, most unlikely first. It leaves the words list undisturbed.
+func typos() []*Word {
 	// Uniq the list: show each word only once; also drop known words.
-	sort.Sort(ByWord(words))
-	out := words[0:0]
+	list := make([]*Word, len(words))
+	copy(list, words)
+	sort.Sort(ByWord(list))
+	out := list[0:0]
 	prev := " "
-	for _, word := range words {
-		if word.text == prev {
+	for _, word := range list {
+		if word.text() == prev {
 			continue
 		}
-		if known[*word.lower] {
+		if !word.candidate() {
 			continue
 		}
 		out = append(out, word)
-		prev = word.text
+		prev = word.text()
 	}
-	words = out
-	// Sort the words by unlikelihood and print the top few.
-	sort.Sort(ByScore(words))
-	for i, w := range words {
-		if i >= *nTypos {
-			break
-		}
+	list = out
+	// Sort the words by unlikelihood and drop the likely ones.
+	sort.Sort(ByScore(list))
+	if *topPercent > 0 {
+		n := int(math.Ceil(float64(len(list)) * *topPercent / 100))
+		return list[:min(n, len(list))]
+	}
+	for i, w := range list {
 		if w.score < *threshold {
-			break
+			return addOCRErrors(list[:i], list[i:])
+		}
+	}
+	return list
+}
+
+// spell prints the unlikely words as text.
+func spell(list []*Word) {
+	if *columns {
+		printColumns(list)
+		return
+	}
+	for _, w := range list {
+		printFinding(w, diagnosis(w)+lineNote(w))
+	}
+}
+
+// printColumns prints the scores and words in three columns, reading down
+// each column in turn, without location information.
+func printColumns(list []*Word) {
+	const ncol = 3
+	rows := (len(list) + ncol - 1) / ncol
+	tw := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < ncol; c++ {
+			i := c*rows + r
+			if i >= len(list) {
+				break
+			}
+			if c > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprintf(tw, "%3d %s%s", int(list[i].score), countPrefix(list[i]), list[i].text())
 		}
-		fmt.Println(words[i])
+		fmt.Fprint(tw, "\n")
 	}
+	tw.Flush()
 }
 
 /*
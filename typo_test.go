@@ -0,0 +1,33 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		failOver int
+		failed   bool
+		found    int
+		want     int
+	}{
+		{0, false, 0, 0},
+		{0, false, 5, 0},
+		{3, false, 2, 0},
+		{3, false, 3, 1},
+		{3, false, 4, 1},
+		{1, false, 1, 1},
+		{0, true, 0, 2},
+		{3, true, 2, 2},
+		{3, true, 3, 2}, // A failure to read wins over -fail-over.
+	}
+	defer func(n int) { *failOver, failed = n, false }(*failOver)
+	for _, test := range tests {
+		*failOver, failed = test.failOver, test.failed
+		if got := exitStatus(test.found); got != test.want {
+			t.Errorf("-fail-over=%d, failed=%t, %d found: status %d; want %d", test.failOver, test.failed, test.found, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// An argument that is an http or https URL is fetched, and its words are
+// reported against the URL. The markup of an HTML page is blanked out, with
+// its scripts, styles, and code, as for the chapters of an EPUB book.
+
+// addURL adds the words of the page if the file names a URL, and reports
+// whether it did.
+func addURL(file string) bool {
+	if !strings.HasPrefix(file, "http://") && !strings.HasPrefix(file, "https://") {
+		return false
+	}
+	resp, err := http.Get(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s\n", err)
+		failed = true
+		return true
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "typo: %s: %s\n", file, resp.Status)
+		failed = true
+		return true
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: %s: %s\n", file, err)
+		failed = true
+		return true
+	}
+	// Show context from the page as fetched.
+	fileLines[file] = splitLines(string(data))
+	kind, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if kind == "text/html" || kind == "application/xhtml+xml" {
+		data = stripHTML(data)
+	}
+	add(file, bytes.NewReader(data))
+	return true
+}
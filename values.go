@@ -0,0 +1,361 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	valuesMode = flag.Bool("values", false, "check only the string values of .json, .yaml, .yml, and .toml files")
+	valueKeys  = flag.String("keys", "", "with -values, check only the values of keys matching the regular `expression`, such as 'description|title|help'")
+)
+
+func init() {
+	addFilter(filter{name: "values", on: func() bool { return *valuesMode }, selects: valuesFilter})
+}
+
+// keysRE is the compiled -keys expression, if any.
+var keysRE *regexp.Regexp
+
+// setKeys compiles -keys.
+func setKeys() {
+	if *valueKeys == "" {
+		return
+	}
+	re, err := regexp.Compile(*valueKeys)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "typo: -keys: %s\n", err)
+		os.Exit(2)
+	}
+	keysRE = re
+}
+
+// keyOK reports whether the value of the key is to be checked.
+func keyOK(key string) bool {
+	return keysRE == nil || keysRE.MatchString(key)
+}
+
+// valuesFilter returns a filter that blanks out all but the string values
+// of the configuration file, chosen by its suffix, or nil if the suffix is
+// not one of a format it knows, in which case the whole file is checked.
+// Only the values of keys allowed by -keys are kept; the values in a list
+// belong to the key of the list.
+func valuesFilter(file string) lineFilter {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return jsonValuesFilter()
+	case ".yaml", ".yml":
+		return yamlValuesFilter()
+	case ".toml":
+		return tomlValuesFilter()
+	}
+	return nil
+}
+
+// A valueLine is a line of a configuration file being blanked out.
+type valueLine struct {
+	line string
+	b    []byte
+}
+
+func newValueLine(line string) *valueLine {
+	return &valueLine{line, []byte(line)}
+}
+
+// blank blanks out the bytes [i, j), leaving tabs as they are.
+func (v *valueLine) blank(i, j int) {
+	for ; i < j && i < len(v.b); i++ {
+		if v.b[i] != '\t' {
+			v.b[i] = ' '
+		}
+	}
+}
+
+func (v *valueLine) String() string {
+	return string(v.b)
+}
+
+// quoted handles the string starting with the quote q at byte i, keeping
+// its text if keep is set, and returns the index just past its end, or of
+// the end of the line if it does not end there. In strings quoted with ",
+// backslash escapes are blanked out.
+func (v *valueLine) quoted(i int, q string, keep bool) (end int, closed bool) {
+	v.blank(i, i+len(q))
+	return v.until(i+len(q), q, keep)
+}
+
+// until handles the text of a string quoted with q from byte j, as quoted does.
+func (v *valueLine) until(j int, q string, keep bool) (end int, closed bool) {
+	for j < len(v.line) {
+		if strings.HasPrefix(v.line[j:], q) {
+			v.blank(j, j+len(q))
+			return j + len(q), true
+		}
+		if v.line[j] == '\\' && q[0] == '"' {
+			n := 2
+			if j+1 < len(v.line) && (v.line[j+1] == 'u' || v.line[j+1] == 'U') {
+				n = 6
+			}
+			v.blank(j, j+n)
+			j += n
+			continue
+		}
+		if !keep {
+			v.blank(j, j+1)
+		}
+		j++
+	}
+	return len(v.line), false
+}
+
+// jsonValuesFilter returns the filter for JSON.
+func jsonValuesFilter() lineFilter {
+	var stack []string // The keys of the enclosing objects and arrays.
+	key := ""          // The key whose value comes next.
+	return func(line string) string {
+		v := newValueLine(line)
+		for i := 0; i < len(line); {
+			switch c := line[i]; c {
+			case '"':
+				if end := closingQuote(line, i); end > 0 && strings.HasPrefix(strings.TrimLeft(line[end:], " \t"), ":") {
+					key = line[i+1 : end-1]
+					v.blank(i, end)
+					i = end
+					continue
+				}
+				k := key
+				if k == "" && len(stack) > 0 {
+					k = stack[len(stack)-1]
+				}
+				i, _ = v.quoted(i, `"`, keyOK(k))
+				key = ""
+				continue
+			case '{', '[':
+				k := key
+				if k == "" && len(stack) > 0 {
+					k = stack[len(stack)-1]
+				}
+				stack = append(stack, k)
+				key = ""
+			case '}', ']':
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+			case ',':
+				key = ""
+			}
+			v.blank(i, i+1)
+			i++
+		}
+		return v.String()
+	}
+}
+
+// closingQuote returns the index just past the closing quote of the JSON
+// string starting at i, or -1 if it does not end on the line.
+func closingQuote(line string, i int) int {
+	for j := i + 1; j < len(line); j++ {
+		switch line[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1
+		}
+	}
+	return -1
+}
+
+var (
+	// key: at the start of a YAML line, after any list item markers.
+	yamlKey = regexp.MustCompile(`^(\s*(?:-\s+)*)("[^"]*"|'[^']*'|[^\s#'"{\[-][^:#]*?|-[^\s:#][^:#]*?)\s*:(?:\s|$)`)
+	// - at the start of a YAML line.
+	yamlItem = regexp.MustCompile(`^(\s*)(?:-\s+)+`)
+	// A YAML value that is not a string.
+	yamlScalar = regexp.MustCompile(`^(?:[-+]?[0-9][0-9._eExXoOa-fA-F:+-]*|(?i:true|false|yes|no|on|off|null)|~)$`)
+)
+
+// yamlValuesFilter returns the filter for YAML.
+func yamlValuesFilter() lineFilter {
+	type level struct {
+		indent int
+		key    string
+	}
+	var stack []level
+	block := -1 // The indentation of the key of the block scalar we are in, or -1.
+	blockKey := ""
+	top := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return stack[len(stack)-1].key
+	}
+	return func(line string) string {
+		v := newValueLine(line)
+		ind := indent(line)
+		if block >= 0 {
+			if ind < 0 {
+				return line
+			}
+			if ind > block {
+				if !keyOK(blockKey) {
+					v.blank(0, len(line))
+				}
+				return v.String()
+			}
+			block = -1
+		}
+		trimmed := strings.TrimSpace(line)
+		if ind < 0 || strings.HasPrefix(trimmed, "#") || trimmed == "---" || trimmed == "..." || strings.HasPrefix(trimmed, "%") {
+			v.blank(0, len(line))
+			return v.String()
+		}
+		var key string
+		var start, keyIndent int
+		if m := yamlKey.FindStringSubmatchIndex(line); m != nil {
+			keyIndent = m[3]
+			key = strings.Trim(line[m[4]:m[5]], `"'`)
+			for len(stack) > 0 && stack[len(stack)-1].indent >= keyIndent {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, level{keyIndent, key})
+			start = m[1]
+		} else {
+			for len(stack) > 0 && stack[len(stack)-1].indent > ind {
+				stack = stack[:len(stack)-1]
+			}
+			key = top()
+			keyIndent = ind
+			if m := yamlItem.FindStringIndex(line); m != nil {
+				start = m[1]
+			}
+		}
+		v.blank(0, start)
+		value := strings.TrimSpace(line[start:])
+		switch {
+		case value == "":
+		case strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">"):
+			block, blockKey = keyIndent, key
+			v.blank(start, len(line))
+		default:
+			yamlValue(v, start, key)
+		}
+		return v.String()
+	}
+}
+
+// yamlValue handles the value starting at byte i of the line.
+func yamlValue(v *valueLine, i int, key string) {
+	line := v.line
+	for i < len(line) {
+		switch c := line[i]; {
+		case c == '"' || c == '\'':
+			i, _ = v.quoted(i, string(c), keyOK(key))
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			v.blank(i, len(line))
+			return
+		case c == '&' || c == '*' || c == '!':
+			// An anchor, alias, or tag.
+			j := i
+			for j < len(line) && line[j] != ' ' && line[j] != '\t' {
+				j++
+			}
+			v.blank(i, j)
+			i = j
+		case c == ' ' || c == '\t':
+			i++
+		default:
+			// A plain scalar runs to a comment or the end of the line.
+			j := strings.Index(line[i:], " #")
+			if j < 0 {
+				j = len(line)
+			} else {
+				j += i
+			}
+			text := strings.TrimSpace(line[i:j])
+			if !keyOK(key) || yamlScalar.MatchString(text) {
+				v.blank(i, j)
+			} else {
+				for k := i; k < j; k++ {
+					if strings.IndexByte("[]{},", line[k]) >= 0 {
+						v.blank(k, k+1)
+					}
+				}
+			}
+			i = j
+		}
+	}
+}
+
+// A TOML key = at the start of a line.
+var tomlKey = regexp.MustCompile(`^\s*((?:[A-Za-z0-9_-]+|"[^"]*"|'[^']*')(?:\s*\.\s*(?:[A-Za-z0-9_-]+|"[^"]*"|'[^']*'))*)\s*=`)
+
+// tomlValuesFilter returns the filter for TOML.
+func tomlValuesFilter() lineFilter {
+	key := ""  // The key of the value being read.
+	end := ""  // The end of the multi-line string we are in.
+	depth := 0 // The depth of the arrays and inline tables we are in.
+	return func(line string) string {
+		v := newValueLine(line)
+		i := 0
+		if end == "" && depth == 0 {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "#") {
+				v.blank(0, len(line))
+				return v.String()
+			}
+			m := tomlKey.FindStringSubmatchIndex(line)
+			if m == nil {
+				v.blank(0, len(line))
+				return v.String()
+			}
+			k := line[m[2]:m[3]]
+			if j := strings.LastIndexByte(k, '.'); j >= 0 {
+				k = k[j+1:]
+			}
+			key = strings.Trim(strings.TrimSpace(k), `"'`)
+			v.blank(0, m[1])
+			i = m[1]
+		}
+		for i < len(line) {
+			if end != "" {
+				var closed bool
+				i, closed = v.until(i, end, keyOK(key))
+				if closed {
+					end = ""
+				}
+				continue
+			}
+			switch c := line[i]; c {
+			case '"', '\'':
+				q := string(c)
+				if strings.HasPrefix(line[i:], q+q+q) {
+					q += q + q
+				}
+				j, closed := v.quoted(i, q, keyOK(key))
+				if !closed && len(q) == 3 {
+					end = q
+				}
+				i = j
+				continue
+			case '#':
+				v.blank(i, len(line))
+				return v.String()
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+			}
+			v.blank(i, i+1)
+			i++
+		}
+		return v.String()
+	}
+}
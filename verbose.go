@@ -0,0 +1,38 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var verbose = flag.Bool("v", false, "log on standard error which word lists, filters, model, and corpus are in use")
+
+// logf prints the message on standard error if -v is set.
+func logf(format string, args ...interface{}) {
+	if *verbose {
+		fmt.Fprintf(os.Stderr, "typo: "+format+"\n", args...)
+	}
+}
+
+// logFilters logs the filters applied to the input.
+func logFilters() {
+	if !*verbose {
+		return
+	}
+	var names []string
+	for _, f := range filters {
+		if f.on() {
+			names = append(names, f.name)
+		}
+	}
+	if len(names) == 0 {
+		names = append(names, "none")
+	}
+	logf("filters: %s", strings.Join(names, ", "))
+}
@@ -0,0 +1,65 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"robpike.io/cmd/typo/dict"
+	"robpike.io/cmd/typo/trigram"
+)
+
+// The word-list model holds digram and trigram statistics computed at
+// startup from the built-in English word list, each word counted once. It
+// is not trained on running text: a common word such as "the" counts no
+// more than a rare one, so its statistics are those of English spelling
+// rather than of English prose. With -wordlist, its counts, multiplied by
+// the weight, are added to those of the input, as a model's are with
+// -model; it needs no file, and it helps most with short input. With
+// -wordlist-only, the input is scored against it alone, as with -corpus.
+// For statistics of running text, train a model with typo train.
+
+var (
+	wordlistWeight = flag.Float64("wordlist", 0, "add the statistics of the built-in English word list, each word counted once and multiplied by `weight`, to those of the input")
+	wordlistOnly   = flag.Bool("wordlist-only", false, "score the input against the statistics of the built-in English word list alone")
+)
+
+// wordlist holds the weighted statistics of the word-list model, if it is in use.
+var wordlist *trigram.Table
+
+// loadWordlist builds the word-list model if it is asked for.
+func loadWordlist() {
+	if *wordlistWeight == 0 && !*wordlistOnly {
+		return
+	}
+	if *wordlistWeight < 0 {
+		fmt.Fprintf(os.Stderr, "typo: negative -wordlist weight %g\n", *wordlistWeight)
+		os.Exit(2)
+	}
+	if *wordlistOnly && *corpusPath != "" {
+		fmt.Fprintf(os.Stderr, "typo: -wordlist-only and -corpus are exclusive\n")
+		os.Exit(2)
+	}
+	list := dict.Words(dict.Bundled["en"])
+	t := trigram.New()
+	for _, w := range list {
+		t.Add(form(w))
+	}
+	if *wordlistOnly {
+		logf("word-list model: %d words, scored alone", len(list))
+		t.SetCombination(combination())
+		t.SetZero(zeroHandling())
+		t.SetReference(true)
+		corpus = t
+		table = t
+		return
+	}
+	logf("word-list model: %d words, weight %g", len(list), *wordlistWeight)
+	wordlist = trigram.New()
+	wordlist.MergeScaled(t, *wordlistWeight)
+	table = newTable()
+}